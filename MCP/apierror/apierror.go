@@ -0,0 +1,212 @@
+// Package apierror decodes Docker Hub API error responses into one structure,
+// whatever error schema the endpoint uses, so tools can return errors as
+// structured content with a short remediation hint.
+package apierror
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxMessage caps messages taken from non-JSON bodies such as HTML error pages.
+const maxMessage = 500
+
+// Error is an API error response.
+type Error struct {
+	Status     int                 `json:"status"`
+	StatusText string              `json:"status_text"`
+	Message    string              `json:"message,omitempty"`
+	Fields     map[string][]string `json:"fields,omitempty"`    // Field-level validation errors
+	Txnid      string              `json:"txnid,omitempty"`     // Transaction ID to quote to Docker support
+	Operation  string              `json:"operation,omitempty"` // API operation named in errinfo
+	Retryable  bool                `json:"retryable"`
+	RetryAfter int                 `json:"retry_after_seconds,omitempty"`
+	Hint       string              `json:"hint,omitempty"`
+	Details    map[string]any      `json:"details,omitempty"` // Other fields of the response, e.g. login_2fa_token
+}
+
+// Error returns a one-line summary of e.
+func (e *Error) Error() string {
+	s := fmt.Sprintf("API error: %d %s", e.Status, e.StatusText)
+	if e.Message != "" {
+		s += ": " + e.Message
+	}
+	if e.Txnid != "" {
+		s += " (txnid " + e.Txnid + ")"
+	}
+	return s
+}
+
+// Result returns e as an error tool result. The text content repeats the
+// structured content for clients that only show text.
+func (e *Error) Result() *mcp.CallToolResult {
+	text := e.Error()
+	if data, err := json.MarshalIndent(e, "", "  "); err == nil {
+		text += "\n" + string(data)
+	}
+	result := mcp.NewToolResultStructured(e, text)
+	result.IsError = true
+	return result
+}
+
+// Result decodes the error response resp with body and returns it as an error
+// tool result.
+func Result(resp *http.Response, body []byte) *mcp.CallToolResult {
+	return Parse(resp, body).Result()
+}
+
+// knownKeys are response fields Parse maps onto Error.
+var knownKeys = map[string]bool{"message": true, "detail": true, "text": true, "fields": true, "txnid": true}
+
+// Parse decodes the error response resp with body. It understands the spec's
+// ErrorResponse, PostNamespacesDeleteImagesResponseError, ValueError,
+// ErrorDetail, Scimerror and rpcStatus schemas; other bodies become the message.
+func Parse(resp *http.Response, body []byte) *Error {
+	e := &Error{Status: resp.StatusCode, StatusText: http.StatusText(resp.StatusCode)}
+
+	var raw map[string]any
+	if json.Unmarshal(body, &raw) != nil {
+		e.Message = plainMessage(body)
+	} else {
+		// Each model picks out its own fields; mismatched types in the others are ignored
+		var (
+			errResp  models.ErrorResponse
+			delErr   models.PostNamespacesDeleteImagesResponseError
+			valueErr models.ValueError
+			detail   models.ErrorDetail
+			scimErr  models.Scimerror
+			rpc      models.RpcStatus
+		)
+		for _, v := range []any{&errResp, &delErr, &valueErr, &detail, &scimErr, &rpc} {
+			json.Unmarshal(body, v)
+		}
+		e.Message = firstNonEmpty(errResp.Message, delErr.Message, rpc.Message, detail.Detail, scimErr.Detail, valueErr.Text)
+		e.Txnid = firstNonEmpty(errResp.Txnid, delErr.Txnid, errResp.Errinfo.Api_call_txnid)
+		e.Operation = errResp.Errinfo.Api_call_name
+		e.Fields = fieldErrors(valueErr.Fields)
+		if e.Message == "" && e.Fields == nil && resp.StatusCode == http.StatusBadRequest {
+			// Some endpoints answer with a bare {"field": ["problem"]} object
+			if e.Fields = fieldErrors(raw); e.Fields != nil {
+				raw = nil
+			}
+		}
+
+		for key, value := range raw {
+			if knownKeys[key] || key == "errinfo" && isErrorInfo(value) {
+				continue
+			}
+			if e.Details == nil {
+				e.Details = make(map[string]any)
+			}
+			e.Details[key] = value
+		}
+		if e.Message == "" && e.Fields == nil {
+			e.Message = plainMessage(body)
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		e.Retryable = true
+	}
+	e.RetryAfter = retryAfter(resp.Header, time.Now())
+	e.Hint = hint(e, resp.Request)
+	return e
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func plainMessage(body []byte) string {
+	s := strings.TrimSpace(string(body))
+	if len(s) > maxMessage {
+		s = s[:maxMessage] + "..."
+	}
+	return s
+}
+
+// fieldErrors converts {"field": "problem"} and {"field": ["problem", ...]}
+// maps. It returns nil unless every value is a string or a list of strings.
+func fieldErrors(m map[string]any) map[string][]string {
+	if len(m) == 0 {
+		return nil
+	}
+	fields := make(map[string][]string, len(m))
+	for name, value := range m {
+		switch v := value.(type) {
+		case string:
+			fields[name] = []string{v}
+		case []any:
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil
+				}
+				fields[name] = append(fields[name], s)
+			}
+		default:
+			return nil
+		}
+	}
+	return fields
+}
+
+// isErrorInfo reports whether value holds only ErrorInfo fields, which Parse
+// already maps. Other errinfo objects, such as delete-images failures, are kept
+// in Details.
+func isErrorInfo(value any) bool {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return false
+	}
+	for key := range m {
+		if !strings.HasPrefix(key, "api_call_") {
+			return false
+		}
+	}
+	return true
+}
+
+// retryAfter returns the seconds to wait before retrying, from Retry-After or
+// X-RateLimit-Reset.
+func retryAfter(h http.Header, now time.Time) int {
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			return secs
+		}
+		if t, err := http.ParseTime(v); err == nil && t.After(now) {
+			return int(t.Sub(now).Seconds()) + 1
+		}
+	}
+	if v := h.Get("X-RateLimit-Reset"); v != "" {
+		if reset, err := strconv.ParseInt(v, 10, 64); err == nil && reset > now.Unix() {
+			return int(reset - now.Unix())
+		}
+	}
+	return 0
+}
+
+// fieldNames lists the fields with errors in a stable order.
+func fieldNames(fields map[string][]string) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,215 @@
+package apierror
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func response(status int, method, path string) *http.Response {
+	req, _ := http.NewRequest(method, "https://hub.docker.com"+path, nil)
+	req.Header.Set("Authorization", "Bearer token")
+	return &http.Response{StatusCode: status, Header: http.Header{}, Request: req}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		path   string
+		header http.Header
+		body   string
+		want   Error // Status, StatusText and Hint are not compared
+	}{
+		{
+			name:   "ErrorResponse",
+			status: http.StatusForbidden,
+			path:   "/v2/orgs/acme/settings",
+			body:   `{"txnid": "t-1", "message": "forbidden", "errinfo": {"api_call_docker_id": "u-1", "api_call_name": "GetOrgSettings", "api_call_start": "2026-01-01T00:00:00Z", "api_call_txnid": "t-1"}}`,
+			want:   Error{Message: "forbidden", Txnid: "t-1", Operation: "GetOrgSettings"},
+		},
+		{
+			name:   "ErrorResponse txnid only in errinfo",
+			status: http.StatusInternalServerError,
+			path:   "/v2/orgs/acme/settings",
+			body:   `{"message": "internal error", "errinfo": {"api_call_txnid": "t-2"}}`,
+			want:   Error{Message: "internal error", Txnid: "t-2", Retryable: true},
+		},
+		{
+			name:   "ValueError",
+			status: http.StatusBadRequest,
+			path:   "/v2/access-tokens",
+			body:   `{"text": "invalid request", "fields": {"token_label": ["required"], "scopes": "unknown scope"}}`,
+			want:   Error{Message: "invalid request", Fields: map[string][]string{"token_label": {"required"}, "scopes": {"unknown scope"}}},
+		},
+		{
+			name:   "bare field errors",
+			status: http.StatusBadRequest,
+			path:   "/v2/access-tokens",
+			body:   `{"token_label": ["too long", "bad characters"]}`,
+			want:   Error{Fields: map[string][]string{"token_label": {"too long", "bad characters"}}},
+		},
+		{
+			name:   "object on another status is not field errors",
+			status: http.StatusConflict,
+			path:   "/v2/access-tokens",
+			body:   `{"token_label": ["exists"]}`,
+			want:   Error{Message: `{"token_label": ["exists"]}`, Details: map[string]any{"token_label": []any{"exists"}}},
+		},
+		{
+			name:   "Scimerror",
+			status: http.StatusNotFound,
+			path:   "/v2/scim/2.0/Users/1",
+			body:   `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:Error"], "status": "404", "detail": "User not found"}`,
+			want: Error{Message: "User not found", Details: map[string]any{
+				"schemas": []any{"urn:ietf:params:scim:api:messages:2.0:Error"}, "status": "404",
+			}},
+		},
+		{
+			name:   "PostNamespacesDeleteImagesResponseError",
+			status: http.StatusBadRequest,
+			path:   "/v2/namespaces/acme/delete-images",
+			body:   `{"txnid": "t-3", "message": "deletion not possible", "errinfo": {"type": "validation", "details": {"errors": [{"type": "not_found", "info": {"repository": "app", "digest": "sha256:1"}}]}}}`,
+			want: Error{Message: "deletion not possible", Txnid: "t-3", Details: map[string]any{
+				"errinfo": map[string]any{"type": "validation", "details": map[string]any{"errors": []any{
+					map[string]any{"type": "not_found", "info": map[string]any{"repository": "app", "digest": "sha256:1"}},
+				}}},
+			}},
+		},
+		{
+			name:   "login with 2FA",
+			status: http.StatusUnauthorized,
+			path:   "/v2/users/login",
+			body:   `{"detail": "Require secondary authentication on MFA enabled account", "login_2fa_token": "2fa"}`,
+			want:   Error{Message: "Require secondary authentication on MFA enabled account", Details: map[string]any{"login_2fa_token": "2fa"}},
+		},
+		{
+			name:   "rpcStatus",
+			status: http.StatusServiceUnavailable,
+			path:   "/v2/auditlogs/acme",
+			body:   `{"code": 14, "message": "unavailable", "details": []}`,
+			want:   Error{Message: "unavailable", Retryable: true, Details: map[string]any{"code": 14.0, "details": []any{}}},
+		},
+		{
+			name:   "HTML page",
+			status: http.StatusBadGateway,
+			path:   "/v2/repositories/acme/app",
+			body:   "  <html><body>Bad Gateway</body></html>\n",
+			want:   Error{Message: "<html><body>Bad Gateway</body></html>", Retryable: true},
+		},
+		{
+			name:   "long plain text is cut",
+			status: http.StatusInternalServerError,
+			path:   "/v2/repositories/acme/app",
+			body:   strings.Repeat("x", maxMessage+1),
+			want:   Error{Message: strings.Repeat("x", maxMessage) + "...", Retryable: true},
+		},
+		{
+			name:   "empty body",
+			status: http.StatusNotFound,
+			path:   "/v2/repositories/acme/app",
+			want:   Error{},
+		},
+		{
+			name:   "rate limited",
+			status: http.StatusTooManyRequests,
+			path:   "/v2/repositories/acme/app",
+			header: http.Header{"Retry-After": {"30"}},
+			body:   `{"detail": "slow down"}`,
+			want:   Error{Message: "slow down", Retryable: true, RetryAfter: 30},
+		},
+		{
+			name:   "client errors are not retryable",
+			status: http.StatusUnprocessableEntity,
+			path:   "/v2/repositories/acme/app",
+			body:   `{"message": "invalid"}`,
+			want:   Error{Message: "invalid"},
+		},
+		{name: "408", status: http.StatusRequestTimeout, want: Error{Retryable: true}},
+		{name: "425", status: http.StatusTooEarly, want: Error{Retryable: true}},
+		{name: "504", status: http.StatusGatewayTimeout, want: Error{Retryable: true}},
+		{name: "501", status: http.StatusNotImplemented, want: Error{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := response(tt.status, http.MethodGet, tt.path)
+			if tt.header != nil {
+				resp.Header = tt.header
+			}
+			got := Parse(resp, []byte(tt.body))
+			if got.Status != tt.status || got.StatusText != http.StatusText(tt.status) {
+				t.Errorf("status = %d %q", got.Status, got.StatusText)
+			}
+			tt.want.Status, tt.want.StatusText, tt.want.Hint = got.Status, got.StatusText, got.Hint
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Parse =\n%+v\nwant\n%+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   map[string]any
+		want map[string][]string
+	}{
+		{name: "nil", in: nil, want: nil},
+		{name: "empty", in: map[string]any{}, want: nil},
+		{name: "string", in: map[string]any{"name": "required"}, want: map[string][]string{"name": {"required"}}},
+		{name: "list", in: map[string]any{"name": []any{"required", "too short"}}, want: map[string][]string{"name": {"required", "too short"}}},
+		{name: "number", in: map[string]any{"name": "required", "code": 3.0}, want: nil},
+		{name: "list with an object", in: map[string]any{"name": []any{"required", map[string]any{}}}, want: nil},
+		{name: "object", in: map[string]any{"errinfo": map[string]any{"type": "x"}}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fieldErrors(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fieldErrors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{name: "none", header: http.Header{}, want: 0},
+		{name: "seconds", header: http.Header{"Retry-After": {"120"}}, want: 120},
+		{name: "zero seconds", header: http.Header{"Retry-After": {"0"}}, want: 0},
+		{name: "negative seconds", header: http.Header{"Retry-After": {"-5"}}, want: 0},
+		{name: "HTTP date", header: http.Header{"Retry-After": {now.Add(90 * time.Second).Format(http.TimeFormat)}}, want: 91},
+		{name: "HTTP date passed", header: http.Header{"Retry-After": {now.Add(-time.Minute).Format(http.TimeFormat)}}, want: 0},
+		{name: "garbage", header: http.Header{"Retry-After": {"soon"}}, want: 0},
+		{name: "rate limit reset", header: http.Header{"X-Ratelimit-Reset": {"1767268860"}}, want: 60},
+		{name: "rate limit reset passed", header: http.Header{"X-Ratelimit-Reset": {"1767268740"}}, want: 0},
+		{name: "Retry-After wins", header: http.Header{"Retry-After": {"5"}, "X-Ratelimit-Reset": {"1767268860"}}, want: 5},
+		{name: "bad Retry-After falls back", header: http.Header{"Retry-After": {"soon"}, "X-Ratelimit-Reset": {"1767268860"}}, want: 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfter(tt.header, now); got != tt.want {
+				t.Errorf("retryAfter = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResult(t *testing.T) {
+	result := Result(response(http.StatusNotFound, http.MethodGet, "/v2/repositories/acme/app"), []byte(`{"message": "not found", "txnid": "t-9"}`))
+	if !result.IsError {
+		t.Error("IsError = false")
+	}
+	if e, ok := result.StructuredContent.(*Error); !ok || e.Txnid != "t-9" {
+		t.Errorf("StructuredContent = %#v", result.StructuredContent)
+	}
+	if len(result.Content) == 0 {
+		t.Fatal("no text content")
+	}
+}
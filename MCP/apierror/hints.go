@@ -0,0 +1,84 @@
+package apierror
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// requirement describes what a credential needs for the endpoints matching path.
+type requirement struct {
+	method string // Empty matches any method
+	path   *regexp.Regexp
+	hint   string
+}
+
+var requirements = []requirement{
+	{"POST", regexp.MustCompile(`^/v2/namespaces/[^/]+/delete-images$`), "token lacks repo:admin scope, or the account is not on a Pro or Team plan"},
+	{"GET", regexp.MustCompile(`^/v2/namespaces/[^/]+/repositories/[^/]+/images`), "token lacks repo:read scope"},
+	{"", regexp.MustCompile(`^/v2/namespaces/[^/]+/repositories/[^/]+/tags`), "token lacks repo:read scope for this private repository"},
+	{"", regexp.MustCompile(`^/v2/access-tokens`), "access tokens can only be managed with a password login, not a personal access token"},
+	{"", regexp.MustCompile(`^/v2/orgs/[^/]+/settings$`), "only organization owners can use this endpoint"},
+	{"", regexp.MustCompile(`^/v2/auditlogs/`), "only organization owners can use this endpoint"},
+}
+
+// requirementHint explains what the endpoint req was sent to requires.
+func requirementHint(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	for _, r := range requirements {
+		if (r.method == "" || r.method == req.Method) && r.path.MatchString(req.URL.Path) {
+			return r.hint
+		}
+	}
+	return ""
+}
+
+// hint suggests how to resolve e, an error response to req.
+func hint(e *Error, req *http.Request) string {
+	path := ""
+	if req != nil {
+		path = req.URL.Path
+	}
+	switch status := e.Status; {
+	case status == http.StatusUnauthorized && strings.HasSuffix(path, "/users/login"):
+		if _, ok := e.Details["login_2fa_token"]; ok {
+			return "401: two-factor authentication required; call post_v2_users_2fa-login with the code and login_2fa_token"
+		}
+		return "401: wrong username, password or personal access token"
+	case status == http.StatusUnauthorized && strings.HasSuffix(path, "/users/2fa-login"):
+		return "401: wrong or expired code; login_2fa_token is short-lived, so log in again if it has expired"
+	case status == http.StatusUnauthorized && req != nil && req.Header.Get("Authorization") == "":
+		return "401: no credentials were sent; configure a token, basic auth or Docker credentials, or log in"
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		if h := requirementHint(req); h != "" {
+			return fmt.Sprintf("%d: %s", status, h)
+		}
+		if status == http.StatusUnauthorized {
+			return "401: the credential is invalid or has expired"
+		}
+		return "403: the credential is not allowed to perform this operation"
+	case status == http.StatusBadRequest && strings.HasSuffix(path, "/delete-images"):
+		return "400: deletion not possible; see details.errinfo, and repeat warnings in ignore_warnings to proceed (errors cannot be ignored)"
+	case status == http.StatusBadRequest && len(e.Fields) > 0:
+		return "400: fix the invalid fields (" + fieldNames(e.Fields) + ") and retry"
+	case status == http.StatusBadRequest:
+		return "400: the request was rejected; check the arguments"
+	case status == http.StatusNotFound:
+		return "404: not found, or hidden from this credential; private repositories return 404 without read access"
+	case status == http.StatusConflict:
+		return "409: conflicts with the current state; read it again before retrying"
+	case status == http.StatusTooManyRequests && e.RetryAfter > 0:
+		return fmt.Sprintf("429: rate limited; retry after %d seconds", e.RetryAfter)
+	case status == http.StatusTooManyRequests:
+		return "429: rate limited; retry later"
+	case e.Retryable:
+		if e.Txnid != "" {
+			return fmt.Sprintf("%d: temporary Docker Hub error; retry, and quote txnid %s if it persists", status, e.Txnid)
+		}
+		return fmt.Sprintf("%d: temporary Docker Hub error; retry later", status)
+	}
+	return ""
+}
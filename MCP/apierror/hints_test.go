@@ -0,0 +1,67 @@
+package apierror
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHint(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		method string
+		path   string
+		noAuth bool
+		header http.Header
+		body   string
+		want   string // Prefix of the hint; empty means no hint
+	}{
+		{name: "2FA required", status: 401, path: "/v2/users/login", body: `{"login_2fa_token": "2fa"}`, want: "401: two-factor authentication required"},
+		{name: "wrong password", status: 401, path: "/v2/users/login", body: `{"detail": "Incorrect authentication credentials"}`, want: "401: wrong username, password"},
+		{name: "wrong code", status: 401, path: "/v2/users/2fa-login", want: "401: wrong or expired code"},
+		{name: "no credentials", status: 401, path: "/v2/repositories/acme/app", noAuth: true, want: "401: no credentials were sent"},
+		{name: "invalid credential", status: 401, path: "/v2/repositories/acme/app", want: "401: the credential is invalid or has expired"},
+		{name: "delete-images scope", status: 403, method: "POST", path: "/v2/namespaces/acme/delete-images", want: "403: token lacks repo:admin scope"},
+		{name: "images scope", status: 401, path: "/v2/namespaces/acme/repositories/app/images", want: "401: token lacks repo:read scope"},
+		{name: "private tags", status: 403, path: "/v2/namespaces/acme/repositories/app/tags/latest", want: "403: token lacks repo:read scope for this private repository"},
+		{name: "access tokens", status: 403, method: "POST", path: "/v2/access-tokens", want: "403: access tokens can only be managed with a password login"},
+		{name: "org settings", status: 403, path: "/v2/orgs/acme/settings", want: "403: only organization owners"},
+		{name: "audit logs", status: 403, path: "/v2/auditlogs/acme", want: "403: only organization owners"},
+		{name: "method does not match", status: 403, path: "/v2/namespaces/acme/delete-images", want: "403: the credential is not allowed"},
+		{name: "delete-images validation", status: 400, method: "POST", path: "/v2/namespaces/acme/delete-images", body: `{"message": "deletion not possible"}`, want: "400: deletion not possible; see details.errinfo"},
+		{name: "invalid fields", status: 400, path: "/v2/access-tokens", body: `{"fields": {"scopes": ["unknown"], "token_label": ["required"]}}`, want: "400: fix the invalid fields (scopes, token_label) and retry"},
+		{name: "bad request", status: 400, path: "/v2/access-tokens", body: `{"message": "bad"}`, want: "400: the request was rejected"},
+		{name: "not found", status: 404, path: "/v2/repositories/acme/app", want: "404: not found, or hidden"},
+		{name: "conflict", status: 409, path: "/v2/repositories", want: "409: conflicts with the current state"},
+		{name: "rate limited with Retry-After", status: 429, path: "/v2/repositories/acme/app", header: http.Header{"Retry-After": {"7"}}, want: "429: rate limited; retry after 7 seconds"},
+		{name: "rate limited", status: 429, path: "/v2/repositories/acme/app", want: "429: rate limited; retry later"},
+		{name: "server error with txnid", status: 500, path: "/v2/repositories/acme/app", body: `{"txnid": "t-1"}`, want: "500: temporary Docker Hub error; retry, and quote txnid t-1"},
+		{name: "server error", status: 503, path: "/v2/repositories/acme/app", want: "503: temporary Docker Hub error; retry later"},
+		{name: "no hint", status: 422, path: "/v2/repositories/acme/app", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			resp := response(tt.status, method, tt.path)
+			if tt.noAuth {
+				resp.Request.Header.Del("Authorization")
+			}
+			if tt.header != nil {
+				resp.Header = tt.header
+			}
+			got := Parse(resp, []byte(tt.body)).Hint
+			if tt.want == "" && got != "" || !strings.HasPrefix(got, tt.want) {
+				t.Errorf("hint = %q, want %q...", got, tt.want)
+			}
+		})
+	}
+
+	// Without the request only the status is known
+	if got := hint(&Error{Status: http.StatusForbidden}, nil); got != "403: the credential is not allowed to perform this operation" {
+		t.Errorf("hint without request = %q", got)
+	}
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// APIKeys authenticates clients with static keys sent in the X-API-Key header
+// or as a bearer token.
+type APIKeys struct {
+	keys map[[sha256.Size]byte]string // key digest -> client id
+}
+
+// LoadAPIKeys reads a key file with one "<client-id> <key>" pair per line.
+// Blank lines and lines starting with '#' are ignored.
+func LoadAPIKeys(path string) (*APIKeys, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open API keys file: %w", err)
+	}
+	defer f.Close()
+
+	keys := &APIKeys{keys: make(map[[sha256.Size]byte]string)}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"<client-id> <key>\"", path, line)
+		}
+		keys.keys[sha256.Sum256([]byte(fields[1]))] = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read API keys file: %w", err)
+	}
+	return keys, nil
+}
+
+func (k *APIKeys) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		key = bearerToken(r)
+		// JWTs are left to the bearer authenticator.
+		if key == "" || strings.Count(key, ".") == 2 {
+			return nil, ErrNoCredentials
+		}
+	}
+	digest := sha256.Sum256([]byte(key))
+	for d, client := range k.keys {
+		if subtle.ConstantTimeCompare(d[:], digest[:]) == 1 {
+			return &Principal{Subject: client, Method: "api_key"}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown API key", ErrInvalidCredentials)
+}
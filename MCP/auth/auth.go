@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries no credential an Authenticator understands.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned when a credential is present but rejected.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal identifies an authenticated MCP client.
+type Principal struct {
+	Subject string   // Client identity, e.g. the API key's client id or the token's "sub"
+	Method  string   // "api_key", "bearer" or "client_cert"
+	Scopes  []string // Granted OAuth scopes, empty for API keys
+}
+
+// ID names p uniquely across authentication methods as "method:subject",
+// e.g. "bearer:alice". A token's "sub" and an API key's client id are chosen
+// by different parties, so the subject alone could name two clients.
+func (p *Principal) ID() string {
+	return p.Method + ":" + p.Subject
+}
+
+// Authenticator checks the credentials of an inbound HTTP request.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the authenticated client stored in ctx, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// Chain tries each Authenticator in order and returns the first success.
+// A rejected credential stops the chain; missing credentials move on to the next.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	for _, a := range c {
+		p, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return p, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// New builds the authenticators enabled by cfg. It returns nil when inbound
+// authentication is not configured.
+func New(cfg *config.AuthConfig) (Authenticator, error) {
+	var chain Chain
+	if cfg.ClientCertificates {
+		chain = append(chain, ClientCertificates{})
+	}
+	if cfg.APIKeysFile != "" {
+		keys, err := LoadAPIKeys(cfg.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, keys)
+	}
+	if cfg.JWKSFile != "" {
+		jwks, err := LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		audience := cfg.Audience
+		if audience == "" {
+			audience = cfg.ResourceURL
+		}
+		// Without an audience, tokens the issuer mints for any other service would be accepted
+		if audience == "" {
+			return nil, fmt.Errorf("AUTH_JWKS_FILE requires AUTH_AUDIENCE or AUTH_RESOURCE_URL")
+		}
+		chain = append(chain, &BearerAuthenticator{
+			Keys:           jwks,
+			Issuer:         cfg.Issuer,
+			Audience:       audience,
+			RequiredScopes: cfg.RequiredScopes,
+		})
+	}
+	if len(chain) == 0 {
+		return nil, nil
+	}
+	return chain, nil
+}
+
+// Middleware rejects requests that a does not authenticate with a 401 and a
+// Bearer challenge pointing at the protected-resource metadata document.
+// Authenticated requests continue with the Principal in their context.
+func Middleware(a Authenticator, cfg *config.AuthConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.Authenticate(r)
+		if err != nil {
+			challenge(w, MetadataURL(cfg, r), err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+	})
+}
+
+func challenge(w http.ResponseWriter, metadataURL string, err error) {
+	params := []string{`realm="mcp"`, fmt.Sprintf("resource_metadata=%q", metadataURL)}
+	status := http.StatusUnauthorized
+	var scopeErr *InsufficientScopeError
+	switch {
+	case errors.As(err, &scopeErr):
+		status = http.StatusForbidden
+		params = append(params, `error="insufficient_scope"`, fmt.Sprintf("scope=%q", strings.Join(scopeErr.Required, " ")))
+	case !errors.Is(err, ErrNoCredentials):
+		params = append(params, `error="invalid_token"`, fmt.Sprintf("error_description=%q", err.Error()))
+	}
+	w.Header().Set("WWW-Authenticate", "Bearer "+strings.Join(params, ", "))
+	http.Error(w, http.StatusText(status), status)
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
+}
@@ -0,0 +1,17 @@
+package auth
+
+import "net/http"
+
+// ClientCertificates authenticates clients by the TLS client certificate the
+// server verified during the handshake. The principal's subject is the
+// certificate's distinguished name, e.g. "CN=ci-bot,O=Example".
+type ClientCertificates struct{}
+
+func (ClientCertificates) Authenticate(r *http.Request) (*Principal, error) {
+	// VerifiedChains is only populated when the certificate chained to TLS_CLIENT_CA_FILE
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+	return &Principal{Subject: cert.Subject.String(), Method: "client_cert"}, nil
+}
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clockSkew is the leeway allowed when checking exp and nbf.
+const clockSkew = time.Minute
+
+// InsufficientScopeError is returned when a valid token lacks required scopes.
+type InsufficientScopeError struct {
+	Required []string
+}
+
+func (e *InsufficientScopeError) Error() string {
+	return fmt.Sprintf("token lacks required scopes: %s", strings.Join(e.Required, " "))
+}
+
+// BearerAuthenticator validates OAuth 2.1 access tokens in JWT format against a local JWKS.
+type BearerAuthenticator struct {
+	Keys           *JWKS
+	Issuer         string   // Expected "iss", skipped when empty
+	Audience       string   // Expected "aud"; tokens are rejected when it is empty
+	RequiredScopes []string // Scopes that must all be granted
+	Now            func() time.Time
+}
+
+type claims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	ClientID  string   `json:"client_id"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Scope     string   `json:"scope"`
+	Scp       []string `json:"scp"`
+}
+
+// audience accepts both the string and array forms of the "aud" claim.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (b *BearerAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return nil, ErrNoCredentials
+	}
+	c, err := b.verify(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	scopes := c.Scp
+	if c.Scope != "" {
+		scopes = strings.Fields(c.Scope)
+	}
+	var missing []string
+	for _, s := range b.RequiredScopes {
+		if !slices.Contains(scopes, s) {
+			missing = append(missing, s)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &InsufficientScopeError{Required: b.RequiredScopes}
+	}
+
+	subject := c.Subject
+	if subject == "" {
+		subject = c.ClientID
+	}
+	// RBAC and the vault tell clients apart by subject
+	if subject == "" {
+		return nil, fmt.Errorf("%w: token has neither sub nor client_id", ErrInvalidCredentials)
+	}
+	return &Principal{Subject: subject, Method: "bearer", Scopes: scopes}, nil
+}
+
+func (b *BearerAuthenticator) verify(token string) (*claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header")
+	}
+	key, ok := b.Keys.Lookup(header.Kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", header.Kid)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature")
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, fmt.Errorf("malformed token claims")
+	}
+	now := time.Now()
+	if b.Now != nil {
+		now = b.Now()
+	}
+	if c.ExpiresAt == nil || now.After(time.Unix(*c.ExpiresAt, 0).Add(clockSkew)) {
+		return nil, fmt.Errorf("token expired")
+	}
+	if c.NotBefore != nil && now.Add(clockSkew).Before(time.Unix(*c.NotBefore, 0)) {
+		return nil, fmt.Errorf("token not yet valid")
+	}
+	if b.Issuer != "" && c.Issuer != b.Issuer {
+		return nil, fmt.Errorf("unexpected issuer")
+	}
+	if b.Audience == "" || !slices.Contains(c.Audience, b.Audience) {
+		return nil, fmt.Errorf("token not issued for this resource")
+	}
+	return &c, nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	case "EdDSA":
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(pub, []byte(signed), sig) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		var err error
+		if strings.HasPrefix(alg, "PS") {
+			err = rsa.VerifyPSS(pub, hash, digest, sig, nil)
+		} else if strings.HasPrefix(alg, "RS") {
+			err = rsa.VerifyPKCS1v15(pub, hash, digest, sig)
+		} else {
+			err = fmt.Errorf("algorithm %s does not match RSA key", alg)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(alg, "ES") || len(sig) != 2*size {
+			return fmt.Errorf("invalid signature")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("algorithm %s does not match key type", alg)
+	}
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
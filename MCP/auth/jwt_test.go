@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+var b64 = base64.RawURLEncoding
+
+// testKeys are the signing keys of the test issuer, by kid.
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+	ed  ed25519.PrivateKey
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testKeys{rsa: rsaKey, ec: ecKey, ed: edKey}
+}
+
+// writeJWKS writes the public keys as a JWKS file and returns its path.
+func (k *testKeys) writeJWKS(t *testing.T) string {
+	t.Helper()
+	fixed := func(n *big.Int, size int) string {
+		return b64.EncodeToString(n.FillBytes(make([]byte, size)))
+	}
+	doc := map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64.EncodeToString(k.rsa.N.Bytes()), "e": b64.EncodeToString(big.NewInt(int64(k.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": fixed(k.ec.X, 32), "y": fixed(k.ec.Y, 32)},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64.EncodeToString(k.ed.Public().(ed25519.PublicKey))},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+	}}
+	data, _ := json.Marshal(doc)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// sign returns a compact JWT for claims signed with the key kid using alg.
+func (k *testKeys) sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	var sig []byte
+	var err error
+	switch alg {
+	case "RS256":
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, digest[:])
+	case "PS256":
+		sig, err = rsa.SignPSS(rand.Reader, k.rsa, crypto.SHA256, digest[:], nil)
+	case "ES256":
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k.ec, digest[:])
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case "EdDSA":
+		sig = ed25519.Sign(k.ed, []byte(signed))
+	default:
+		sig = []byte("unsigned")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + b64.EncodeToString(sig)
+}
+
+func TestBearerAuthenticator(t *testing.T) {
+	keys := newTestKeys(t)
+	jwks, err := LoadJWKS(keys.writeJWKS(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_800_000_000, 0)
+	valid := func(extra map[string]any) map[string]any {
+		c := map[string]any{"iss": "https://issuer.example", "sub": "alice", "aud": "https://mcp.example/mcp", "exp": now.Add(time.Hour).Unix(), "scope": "hub:read hub:write"}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+	b := &BearerAuthenticator{
+		Keys:           jwks,
+		Issuer:         "https://issuer.example",
+		Audience:       "https://mcp.example/mcp",
+		RequiredScopes: []string{"hub:read"},
+		Now:            func() time.Time { return now },
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		subject string // Expected subject when the token is accepted
+		err     error
+		message string // Part of the error
+	}{
+		{name: "RS256", token: keys.sign(t, "RS256", "rsa", valid(nil)), subject: "alice"},
+		{name: "PS256", token: keys.sign(t, "PS256", "rsa", valid(nil)), subject: "alice"},
+		{name: "ES256", token: keys.sign(t, "ES256", "ec", valid(nil)), subject: "alice"},
+		{name: "EdDSA", token: keys.sign(t, "EdDSA", "ed", valid(nil)), subject: "alice"},
+		{name: "audience array", token: keys.sign(t, "ES256", "ec", valid(map[string]any{"aud": []string{"other", "https://mcp.example/mcp"}})), subject: "alice"},
+		{name: "client_id without sub", token: keys.sign(t, "ES256", "ec", valid(map[string]any{"sub": "", "client_id": "ci"})), subject: "ci"},
+		{name: "neither sub nor client_id", token: keys.sign(t, "ES256", "ec", valid(map[string]any{"sub": ""})), err: ErrInvalidCredentials, message: "neither sub nor client_id"},
+		{name: "scp claim", token: keys.sign(t, "ES256", "ec", valid(map[string]any{"scope": "", "scp": []string{"hub:read"}})), subject: "alice"},
+		{name: "within clock skew", token: keys.sign(t, "ES256", "ec", valid(map[string]any{"exp": now.Add(-30 * time.Second).Unix()})), subject: "alice"},
+		{name: "no token", token: "", err: ErrNoCredentials},
+		{name: "expired", token: keys.sign(t, "ES256", "ec", valid(map[string]any{"exp": now.Add(-time.Hour).Unix()})), err: ErrInvalidCredentials, message: "expired"},
+		{name: "no exp", token: keys.sign(t, "ES256", "ec", valid(map[string]any{"exp": nil})), err: ErrInvalidCredentials, message: "expired"},
+		{name: "not yet valid", token: keys.sign(t, "ES256", "ec", valid(map[string]any{"nbf": now.Add(time.Hour).Unix()})), err: ErrInvalidCredentials, message: "not yet valid"},
+		{name: "other issuer", token: keys.sign(t, "ES256", "ec", valid(map[string]any{"iss": "https://evil.example"})), err: ErrInvalidCredentials, message: "issuer"},
+		{name: "other audience", token: keys.sign(t, "ES256", "ec", valid(map[string]any{"aud": "https://other.example"})), err: ErrInvalidCredentials, message: "not issued for this resource"},
+		{name: "no audience", token: keys.sign(t, "ES256", "ec", valid(map[string]any{"aud": nil})), err: ErrInvalidCredentials, message: "not issued for this resource"},
+		{name: "unknown kid", token: keys.sign(t, "ES256", "nope", valid(nil)), err: ErrInvalidCredentials, message: "unknown signing key"},
+		{name: "encryption key", token: keys.sign(t, "RS256", "enc", valid(nil)), err: ErrInvalidCredentials, message: "unknown signing key"},
+		{name: "algorithm of another key type", token: keys.sign(t, "RS256", "ec", valid(nil)), err: ErrInvalidCredentials, message: "invalid signature"},
+		{name: "none", token: keys.sign(t, "none", "rsa", valid(nil)), err: ErrInvalidCredentials, message: "unsupported signing algorithm"},
+		{name: "malformed", token: "a.b", err: ErrInvalidCredentials, message: "malformed"},
+		{name: "missing scope", token: keys.sign(t, "ES256", "ec", valid(map[string]any{"scope": "hub:write"})), message: "lacks required scopes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/mcp", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			p, err := b.Authenticate(req)
+			if tt.err == nil && tt.message == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if p.Subject != tt.subject || p.Method != "bearer" || p.ID() != "bearer:"+tt.subject {
+					t.Errorf("got %+v, want subject %q", p, tt.subject)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("accepted, want an error")
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.message)
+			}
+		})
+	}
+
+	// A token is never accepted without an expected audience
+	token := keys.sign(t, "ES256", "ec", valid(nil))
+	open := *b
+	open.Audience = ""
+	req, _ := http.NewRequest(http.MethodGet, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if _, err := open.Authenticate(req); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("empty Audience: err = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+func TestNewRequiresAudience(t *testing.T) {
+	jwks := newTestKeys(t).writeJWKS(t)
+	tests := []struct {
+		cfg     config.AuthConfig
+		wantErr bool
+	}{
+		{config.AuthConfig{JWKSFile: jwks}, true},
+		{config.AuthConfig{JWKSFile: jwks, Audience: "https://mcp.example/mcp"}, false},
+		{config.AuthConfig{JWKSFile: jwks, ResourceURL: "https://mcp.example/mcp"}, false},
+		{config.AuthConfig{}, false},
+	}
+	for _, tt := range tests {
+		if _, err := New(&tt.cfg); (err != nil) != tt.wantErr {
+			t.Errorf("New(%+v): err = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadJWKS(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"no signing keys", `{"keys":[{"kty":"RSA","use":"enc","n":"AQAB","e":"AQAB"}]}`, "no signing keys"},
+		{"unknown curve", `{"keys":[{"kty":"EC","crv":"P-192","x":"AA","y":"AA"}]}`, "unsupported curve"},
+		{"short Ed25519 key", `{"keys":[{"kty":"OKP","crv":"Ed25519","x":"AAAA"}]}`, "invalid Ed25519 key"},
+		{"unknown key type", `{"keys":[{"kty":"oct","k":"AAAA"}]}`, "unsupported key type"},
+		{"not JSON", `keys`, "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "jwks.json")
+			os.WriteFile(path, []byte(tt.content), 0o600)
+			if _, err := LoadJWKS(path); err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+// MetadataPath is where the OAuth 2.0 Protected Resource Metadata (RFC 9728) is served.
+const MetadataPath = "/.well-known/oauth-protected-resource"
+
+// MetadataPaths returns the paths the metadata is served at: MetadataPath and,
+// as RFC 9728 has clients look it up, MetadataPath followed by the path of the
+// resource, e.g. "/.well-known/oauth-protected-resource/mcp".
+func MetadataPaths(cfg *config.AuthConfig) []string {
+	resourcePath := "/mcp"
+	if u, err := url.Parse(cfg.ResourceURL); err == nil && u.Host != "" {
+		resourcePath = strings.TrimSuffix(u.Path, "/")
+	}
+	if resourcePath == "" {
+		return []string{MetadataPath}
+	}
+	return []string{MetadataPath, MetadataPath + resourcePath}
+}
+
+// MetadataHandler serves the protected-resource metadata document for this server.
+func MetadataHandler(cfg *config.AuthConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resource := cfg.ResourceURL
+		if resource == "" {
+			resource = requestOrigin(r) + "/mcp"
+		}
+		doc := map[string]any{
+			"resource":                 resource,
+			"bearer_methods_supported": []string{"header"},
+			"resource_name":            "Docker HUB API MCP Server",
+		}
+		if len(cfg.AuthorizationServers) > 0 {
+			doc["authorization_servers"] = cfg.AuthorizationServers
+		}
+		if len(cfg.RequiredScopes) > 0 {
+			doc["scopes_supported"] = cfg.RequiredScopes
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(doc)
+	})
+}
+
+// MetadataURL returns the absolute URL of the metadata document as seen by the client of r.
+func MetadataURL(cfg *config.AuthConfig, r *http.Request) string {
+	if u, err := url.Parse(cfg.ResourceURL); err == nil && u.Host != "" {
+		return u.Scheme + "://" + u.Host + MetadataPath
+	}
+	return requestOrigin(r) + MetadataPath
+}
+
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
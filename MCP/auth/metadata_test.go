@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+func TestMetadataPaths(t *testing.T) {
+	tests := []struct {
+		name        string
+		resourceURL string
+		want        []string
+	}{
+		{name: "default resource", want: []string{MetadataPath, MetadataPath + "/mcp"}},
+		{name: "configured resource", resourceURL: "https://mcp.example/hub/mcp", want: []string{MetadataPath, MetadataPath + "/hub/mcp"}},
+		{name: "resource without a path", resourceURL: "https://mcp.example/", want: []string{MetadataPath}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MetadataPaths(&config.AuthConfig{ResourceURL: tt.resourceURL}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MetadataPaths = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          *config.AuthConfig
+		wantResource string
+		wantServers  bool
+	}{
+		{name: "resource from the request", cfg: &config.AuthConfig{}, wantResource: "http://mcp.example:8080/mcp"},
+		{
+			name:         "configured resource and servers",
+			cfg:          &config.AuthConfig{ResourceURL: "https://hub.example/mcp", AuthorizationServers: []string{"https://issuer.example"}},
+			wantResource: "https://hub.example/mcp",
+			wantServers:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			for _, path := range MetadataPaths(tt.cfg) {
+				mux.Handle(path, MetadataHandler(tt.cfg))
+			}
+			for _, path := range []string{MetadataPath, MetadataPath + "/mcp"} {
+				w := httptest.NewRecorder()
+				mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://mcp.example:8080"+path, nil))
+				if w.Code != http.StatusOK {
+					t.Fatalf("%s: status %d", path, w.Code)
+				}
+				var doc struct {
+					Resource string   `json:"resource"`
+					Servers  []string `json:"authorization_servers"`
+				}
+				if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+					t.Fatal(err)
+				}
+				if doc.Resource != tt.wantResource || (len(doc.Servers) > 0) != tt.wantServers {
+					t.Errorf("%s: got %+v", path, doc)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+// Package cache keeps the upstream responses of GET tools so repeated calls
+// within a conversation do not spend the API rate limit.
+//
+// Responses are keyed by the credentials a request carries and its URL, so
+// callers never share each other's responses. A response is served as is
+// until its tool's TTL passes; after that it is revalidated with
+// If-None-Match or If-Modified-Since, and a 304 serves it again for another
+// TTL. Any other method invalidates the cached responses of the resource it
+// changed and everything below it, e.g. deleting images invalidates every
+// response under /v2/namespaces/{namespace}.
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+// Default is the cache the upstream client uses. main replaces it with one
+// using the configured TTLs and store.
+var Default = mustNew(config.DefaultCacheConfig())
+
+// revalidateFor is how long a response with a validator is kept past its TTL
+// so a later call can revalidate it instead of fetching it again.
+const revalidateFor = time.Hour
+
+// Cache serves GET responses from a store.
+type Cache struct {
+	cfg   config.CacheConfig
+	store store
+	now   func() time.Time
+}
+
+// New returns a cache keeping responses in files under cfg.Dir, which is
+// created when missing, or in memory when it is empty.
+func New(cfg *config.CacheConfig) (*Cache, error) {
+	var s store = newMemoryStore(cfg.MaxEntries)
+	if cfg.Dir != "" {
+		disk, err := newDiskStore(cfg.Dir, cfg.MaxEntries)
+		if err != nil {
+			return nil, err
+		}
+		s = disk
+	}
+	return &Cache{cfg: *cfg, store: s, now: time.Now}, nil
+}
+
+// Prune drops the responses that can no longer be served: those past their
+// TTL without a validator and those kept past it for longer than
+// revalidateFor.
+func (c *Cache) Prune() {
+	now := c.now()
+	c.store.drop(func(e entry) bool {
+		if e.validator() {
+			return now.After(e.Expires.Add(revalidateFor))
+		}
+		return now.After(e.Expires)
+	})
+}
+
+// PruneEvery prunes the cache every interval until stop is closed; an interval
+// of 0 returns at once.
+func (c *Cache) PruneEvery(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			c.Prune()
+		}
+	}
+}
+
+func mustNew(cfg *config.CacheConfig) *Cache {
+	c, err := New(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// TTL returns how long responses of tool are served without asking the API;
+// 0 means they are not cached.
+func (c *Cache) TTL(tool string) time.Duration {
+	if c.cfg.TTL <= 0 {
+		return 0
+	}
+	if ttl, ok := c.cfg.TTLs[tool]; ok {
+		return ttl
+	}
+	return c.cfg.TTL
+}
+
+type callKey struct{}
+
+// call is the tool call a request is made for.
+type call struct {
+	tool  string
+	fresh bool // The caller asked for no_cache
+}
+
+func withCall(ctx context.Context, c call) context.Context {
+	return context.WithValue(ctx, callKey{}, c)
+}
+
+func callFromContext(ctx context.Context) (call, bool) {
+	c, ok := ctx.Value(callKey{}).(call)
+	return c, ok
+}
+
+// RoundTrip sends req with next unless a cached response can answer it.
+// Only GET requests made for a tool call with a TTL are cached; requests
+// carrying their own conditional headers are passed through.
+func (c *Cache) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		resp, err := next.RoundTrip(req)
+		c.invalidate(req.URL)
+		return resp, err
+	}
+	call, ok := callFromContext(req.Context())
+	ttl := c.TTL(call.tool)
+	if !ok || req.Method != http.MethodGet || ttl <= 0 || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return next.RoundTrip(req)
+	}
+	key := keyFor(req)
+	cached, found := c.store.get(key)
+	if found && !call.fresh && c.now().Before(cached.Expires) {
+		return cached.response(req), nil
+	}
+	if found && cached.validator() {
+		req = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if modified := cached.Header.Get("Last-Modified"); modified != "" {
+			req.Header.Set("If-Modified-Since", modified)
+		}
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if found && resp.StatusCode == http.StatusNotModified {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		// A 304 carries the current rate limit and validators
+		cached.Header = cached.Header.Clone()
+		for name, values := range resp.Header {
+			cached.Header[name] = values
+		}
+		cached.Expires = c.now().Add(ttl)
+		c.store.put(key, cached)
+		return cached.response(req), nil
+	}
+	if resp.StatusCode != http.StatusOK || strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	c.store.put(key, entry{
+		URL:     req.URL.String(),
+		Status:  resp.StatusCode,
+		Header:  resp.Header.Clone(),
+		Body:    body,
+		Expires: c.now().Add(ttl),
+	})
+	return resp, nil
+}
+
+// invalidate drops the cached responses for the resource a request to u
+// changed: its parent, e.g. /v2/orgs/{name} for /v2/orgs/{name}/settings,
+// and everything below it. The collection a path starts with, such as
+// /v2/access-tokens, is the widest scope.
+func (c *Cache) invalidate(u *url.URL) {
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) > 2 {
+		segments = segments[:len(segments)-1]
+	}
+	scope := "/" + strings.Join(segments, "/")
+	origin := u.Scheme + "://" + u.Host
+	c.store.drop(func(e entry) bool {
+		cached, err := url.Parse(e.URL)
+		if err != nil {
+			return true
+		}
+		return cached.Scheme+"://"+cached.Host == origin &&
+			(cached.Path == scope || strings.HasPrefix(cached.Path, scope+"/"))
+	})
+}
+
+// keyFor identifies req by the credentials it carries and its URL. The
+// credentials are hashed, so they are never written to the store.
+func keyFor(req *http.Request) string {
+	h := sha256.New()
+	for _, part := range []string{req.Header.Get("Authorization"), req.Header.Get("X-API-Key"), req.Header.Get("Accept"), req.URL.String()} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// entry is a cached response.
+type entry struct {
+	URL     string      `json:"url"`
+	Status  int         `json:"status"`
+	Header  http.Header `json:"header"`
+	Body    []byte      `json:"body"`
+	Expires time.Time   `json:"expires"`
+}
+
+// validator reports whether e can be revalidated instead of fetched again.
+func (e entry) validator() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+func (e entry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
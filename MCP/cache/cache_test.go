@@ -0,0 +1,303 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const tagsTool = "get_v2_namespaces_namespace_repositories_repository_tags"
+
+// upstream answers requests like the API and counts them.
+type upstream struct {
+	calls  int
+	header http.Header // Headers of every 200 response
+	last   *http.Request
+}
+
+func (u *upstream) RoundTrip(req *http.Request) (*http.Response, error) {
+	u.calls++
+	u.last = req
+	status := http.StatusOK
+	if etag := u.header.Get("ETag"); etag != "" && req.Header.Get("If-None-Match") == etag {
+		status = http.StatusNotModified
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     u.header.Clone(),
+		Body:       io.NopCloser(strings.NewReader(req.URL.Path)),
+		Request:    req,
+	}, nil
+}
+
+func newTestCache(t *testing.T, dir string, max int) (*Cache, *time.Time) {
+	t.Helper()
+	cfg := config.DefaultCacheConfig()
+	cfg.Dir, cfg.MaxEntries = dir, max
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func get(t *testing.T, c *Cache, next http.RoundTripper, ctx context.Context, method, url, auth string) string {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", auth)
+	resp, err := c.RoundTrip(req, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestRoundTrip(t *testing.T) {
+	const (
+		tags  = "https://hub.example/v2/namespaces/library/repositories/alpine/tags"
+		other = "https://hub.example/v2/namespaces/docker/repositories/cli/tags"
+	)
+	tool := withCall(context.Background(), call{tool: tagsTool})
+	tests := []struct {
+		name   string
+		header http.Header
+		// after runs between the first and second GET of tags
+		after     func(c *Cache, u *upstream, now *time.Time)
+		ctx       context.Context
+		auth      string
+		wantCalls int
+		wantCond  string // If-None-Match of the second request
+	}{
+		{name: "within TTL", ctx: tool, wantCalls: 1},
+		{name: "another credential", ctx: tool, auth: "Bearer other", wantCalls: 2},
+		{name: "not a tool call", ctx: context.Background(), wantCalls: 2},
+		{name: "no_cache", ctx: withCall(context.Background(), call{tool: tagsTool, fresh: true}), wantCalls: 2},
+		{name: "no-store", header: http.Header{"Cache-Control": {"no-store"}}, ctx: tool, wantCalls: 2},
+		{
+			name: "expired without validator", ctx: tool, wantCalls: 2,
+			after: func(_ *Cache, _ *upstream, now *time.Time) { *now = now.Add(2 * time.Minute) },
+		},
+		{
+			name: "expired and revalidated", header: http.Header{"Etag": {`"v1"`}}, ctx: tool, wantCalls: 2, wantCond: `"v1"`,
+			after: func(_ *Cache, _ *upstream, now *time.Time) { *now = now.Add(2 * time.Minute) },
+		},
+		{
+			name: "invalidated by a change", ctx: tool, wantCalls: 3,
+			after: func(c *Cache, u *upstream, _ *time.Time) {
+				get(t, c, u, context.Background(), http.MethodDelete, "https://hub.example/v2/namespaces/library/repositories/alpine", "")
+			},
+		},
+		{
+			name: "change elsewhere", ctx: tool, wantCalls: 2,
+			after: func(c *Cache, u *upstream, _ *time.Time) {
+				get(t, c, u, context.Background(), http.MethodDelete, other, "")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, now := newTestCache(t, "", 10)
+			u := &upstream{header: tt.header}
+			if body := get(t, c, u, tool, http.MethodGet, tags, "Bearer token"); !strings.HasSuffix(tags, body) {
+				t.Fatalf("body = %q", body)
+			}
+			if tt.after != nil {
+				tt.after(c, u, now)
+			}
+			auth := tt.auth
+			if auth == "" {
+				auth = "Bearer token"
+			}
+			if body := get(t, c, u, tt.ctx, http.MethodGet, tags, auth); !strings.HasSuffix(tags, body) {
+				t.Errorf("body = %q", body)
+			}
+			if u.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", u.calls, tt.wantCalls)
+			}
+			if cond := u.last.Header.Get("If-None-Match"); cond != tt.wantCond {
+				t.Errorf("If-None-Match = %q, want %q", cond, tt.wantCond)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	for _, dir := range []string{"", "disk"} {
+		t.Run("store "+dir, func(t *testing.T) {
+			if dir != "" {
+				dir = t.TempDir()
+			}
+			c, now := newTestCache(t, dir, 10)
+			start := *now
+			entries := map[string]entry{
+				"fresh":               {URL: "https://hub.example/fresh", Expires: start.Add(time.Hour)},
+				"expired":             {URL: "https://hub.example/expired", Expires: start.Add(-time.Second)},
+				"revalidatable":       {URL: "https://hub.example/etag", Header: http.Header{"Etag": {`"1"`}}, Expires: start.Add(-time.Minute)},
+				"revalidatable, old":  {URL: "https://hub.example/old", Header: http.Header{"Last-Modified": {"Mon, 01 Jan 2024 00:00:00 GMT"}}, Expires: start.Add(-revalidateFor - time.Second)},
+				"expired, no headers": {URL: "https://hub.example/none", Expires: start.Add(-time.Hour)},
+			}
+			for key, e := range entries {
+				c.store.put(key, e)
+			}
+			c.Prune()
+			tests := []struct {
+				key  string
+				kept bool
+			}{
+				{"fresh", true},
+				{"expired", false},
+				{"revalidatable", true},
+				{"revalidatable, old", false},
+				{"expired, no headers", false},
+			}
+			for _, tt := range tests {
+				if _, ok := c.store.get(tt.key); ok != tt.kept {
+					t.Errorf("%s: kept = %v, want %v", tt.key, ok, tt.kept)
+				}
+			}
+		})
+	}
+}
+
+func TestPruneEvery(t *testing.T) {
+	c, _ := newTestCache(t, "", 10)
+	c.store.put("expired", entry{URL: "https://hub.example/expired"})
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		c.PruneEvery(time.Millisecond, stop)
+		close(done)
+	}()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if _, ok := c.store.get("expired"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expired entry was not pruned")
+		}
+	}
+	close(stop)
+	<-done
+
+	// An interval of 0 turns pruning off
+	returned := make(chan struct{})
+	go func() {
+		c.PruneEvery(0, nil)
+		close(returned)
+	}()
+	select {
+	case <-returned:
+	case <-time.After(5 * time.Second):
+		t.Fatal("PruneEvery(0) did not return")
+	}
+}
+
+func TestDiskStoreLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  int // Files in the directory before it is opened
+		puts      int
+		max       int
+		wantFiles int
+	}{
+		{name: "below max", puts: 5, max: 10, wantFiles: 5},
+		{name: "at max", puts: 10, max: 10, wantFiles: 10},
+		{name: "over max trims to nine tenths", puts: 11, max: 10, wantFiles: 9},
+		{name: "counts files already there", existing: 8, puts: 3, max: 10, wantFiles: 9},
+		{name: "max of one", puts: 3, max: 1, wantFiles: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			old := time.Now().Add(-time.Hour)
+			for i := 0; i < tt.existing; i++ {
+				path := filepath.Join(dir, "existing"+string(rune('a'+i))+".json")
+				if err := os.WriteFile(path, []byte(`{}`), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				os.Chtimes(path, old, old)
+			}
+			s, err := newDiskStore(dir, tt.max)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < tt.puts; i++ {
+				s.put("key"+string(rune('a'+i)), entry{URL: "https://hub.example/"})
+				// Rewriting a key does not add a file
+				s.put("key"+string(rune('a'+i)), entry{URL: "https://hub.example/"})
+			}
+			if n := len(s.files()); n != tt.wantFiles || s.count != tt.wantFiles {
+				t.Errorf("files = %d, count = %d, want %d", n, s.count, tt.wantFiles)
+			}
+			last := "key" + string(rune('a'+tt.puts-1))
+			if _, ok := s.get(last); !ok {
+				t.Errorf("newest entry %s was dropped", last)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		tool      string
+		args      map[string]any
+		wantCall  *call
+		wantArgs  int
+		wantError bool
+	}{
+		{name: "cached tool", tool: tagsTool, args: map[string]any{"namespace": "library"}, wantCall: &call{tool: tagsTool}, wantArgs: 1},
+		{name: "no_cache true", tool: tagsTool, args: map[string]any{"namespace": "library", Argument: true}, wantCall: &call{tool: tagsTool, fresh: true}, wantArgs: 1},
+		{name: "no_cache string", tool: tagsTool, args: map[string]any{Argument: "false"}, wantCall: &call{tool: tagsTool}},
+		{name: "no_cache invalid", tool: tagsTool, args: map[string]any{Argument: "yes"}, wantError: true},
+		{name: "no_cache number", tool: tagsTool, args: map[string]any{Argument: 1}, wantError: true},
+		{name: "not a GET tool", tool: "post_v2_users_login", args: map[string]any{"username": "alice"}, wantArgs: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *call
+			var args map[string]any
+			handler := Middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				if c, ok := callFromContext(ctx); ok {
+					got = &c
+				}
+				args, _ = request.Params.Arguments.(map[string]any)
+				return mcp.NewToolResultText("ok"), nil
+			})
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tt.tool
+			request.Params.Arguments = tt.args
+			result, err := handler(context.Background(), request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.IsError != tt.wantError {
+				t.Fatalf("IsError = %v, want %v", result.IsError, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+			if (got == nil) != (tt.wantCall == nil) || got != nil && *got != *tt.wantCall {
+				t.Errorf("call = %+v, want %+v", got, tt.wantCall)
+			}
+			if _, ok := args[Argument]; ok || len(args) != tt.wantArgs {
+				t.Errorf("handler args = %v", args)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker-hub-api/mcp-server/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Argument asks a GET tool for a response the API confirmed is current.
+const Argument = "no_cache"
+
+// cached reports whether the API calls of tool can be cached: it reads with
+// GET and has a TTL.
+func cached(tool string) bool {
+	op, ok := validate.Lookup(tool)
+	return ok && op.Method == "GET" && Default.TTL(tool) > 0
+}
+
+// Describe adds the no_cache argument to tools whose responses are cached.
+func Describe(tool *mcp.Tool) {
+	if !cached(tool.Name) {
+		return
+	}
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = make(map[string]any)
+	}
+	tool.InputSchema.Properties[Argument] = map[string]any{
+		"type":        "boolean",
+		"description": fmt.Sprintf("Responses are reused for up to %s. Set to true to have the API confirm the response is current, e.g. right after a change made outside this server.", Default.TTL(tool.Name)),
+	}
+}
+
+// Middleware is a middleware that marks the API calls of cached tools with
+// the tool, so the cache knows their TTL, and removes the no_cache argument
+// before the handler runs.
+func Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !cached(request.Params.Name) {
+			return next(ctx, request)
+		}
+		c := call{tool: request.Params.Name}
+		if args, _ := request.Params.Arguments.(map[string]any); args != nil {
+			if value, ok := args[Argument]; ok {
+				switch v := value.(type) {
+				case bool:
+					c.fresh = v
+				case string:
+					if v != "true" && v != "false" {
+						return mcp.NewToolResultError(Argument + " must be true or false"), nil
+					}
+					c.fresh = v == "true"
+				default:
+					return mcp.NewToolResultError(Argument + " must be true or false"), nil
+				}
+				rest := make(map[string]any, len(args))
+				for name, v := range args {
+					if name != Argument {
+						rest[name] = v
+					}
+				}
+				request.Params.Arguments = rest
+			}
+		}
+		return next(withCall(ctx, c), request)
+	}
+}
@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// store holds cached responses by key.
+type store interface {
+	get(key string) (entry, bool)
+	put(key string, e entry)
+	// drop removes the entries match returns true for.
+	drop(match func(entry) bool)
+}
+
+// memoryStore keeps up to max entries, dropping the oldest beyond it.
+type memoryStore struct {
+	mu      sync.Mutex
+	max     int
+	entries map[string]entry
+	order   []string // Keys, oldest first
+}
+
+func newMemoryStore(max int) *memoryStore {
+	return &memoryStore{max: max, entries: make(map[string]entry)}
+}
+
+func (s *memoryStore) get(key string) (entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	return e, ok
+}
+
+func (s *memoryStore) put(key string, e entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[key]; !ok {
+		s.order = append(s.order, key)
+	}
+	s.entries[key] = e
+	for len(s.order) > s.max {
+		delete(s.entries, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+func (s *memoryStore) drop(match func(entry) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.order[:0]
+	for _, key := range s.order {
+		if match(s.entries[key]) {
+			delete(s.entries, key)
+			continue
+		}
+		kept = append(kept, key)
+	}
+	s.order = kept
+}
+
+// diskStore keeps each entry in a JSON file named after its key, readable
+// only by the server's user, and up to max files, dropping the least
+// recently written beyond it. The files are counted once when the store is
+// opened, so a put only lists the directory when the store is full.
+type diskStore struct {
+	mu    sync.Mutex
+	dir   string
+	max   int
+	count int // Files in dir
+}
+
+func newDiskStore(dir string, max int) (*diskStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	s := &diskStore{dir: dir, max: max}
+	s.count = len(s.files())
+	return s, nil
+}
+
+func (s *diskStore) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
+
+func (s *diskStore) get(key string) (entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return entry{}, false
+	}
+	var e entry
+	if json.Unmarshal(data, &e) != nil {
+		return entry{}, false
+	}
+	return e, true
+}
+
+func (s *diskStore) put(key string, e entry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Write and rename so a reader never sees a partial file
+	tmp, err := os.CreateTemp(s.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	_, statErr := os.Stat(s.path(key))
+	if err != nil || os.Rename(tmp.Name(), s.path(key)) != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if statErr != nil {
+		s.count++
+	}
+	if s.count > s.max {
+		s.prune()
+	}
+}
+
+// prune removes the least recently written entries until a tenth of max is
+// free again, so a full store is not listed on every put.
+func (s *diskStore) prune() {
+	files := s.files()
+	s.count = len(files)
+	keep := s.max - s.max/10
+	if len(files) <= keep {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+	for _, file := range files[:len(files)-keep] {
+		if os.Remove(filepath.Join(s.dir, file.Name())) == nil {
+			s.count--
+		}
+	}
+}
+
+func (s *diskStore) files() []os.FileInfo {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil
+	}
+	var files []os.FileInfo
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), ".json") {
+			continue
+		}
+		if info, err := dirEntry.Info(); err == nil {
+			files = append(files, info)
+		}
+	}
+	return files
+}
+
+func (s *diskStore) drop(match func(entry) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files := s.files()
+	s.count = len(files)
+	for _, file := range files {
+		path := filepath.Join(s.dir, file.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var e entry
+		if (json.Unmarshal(data, &e) != nil || match(e)) && os.Remove(path) == nil {
+			s.count--
+		}
+	}
+}
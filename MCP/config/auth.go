@@ -0,0 +1,44 @@
+package config
+
+import "os"
+
+// AuthConfig controls inbound authentication of MCP clients in HTTP/HTTPS mode.
+type AuthConfig struct {
+	APIKeysFile          string   // File of "<client-id> <key>" lines accepted as static API keys
+	JWKSFile             string   // Local JWKS used to verify OAuth 2.1 bearer tokens
+	Issuer               string   // Expected "iss" claim of bearer tokens
+	Audience             string   // Expected "aud" claim; defaults to ResourceURL
+	ResourceURL          string   // Canonical URL of this MCP server, e.g. https://mcp.example.com/mcp
+	AuthorizationServers []string // Authorization servers advertised in protected-resource metadata
+	RequiredScopes       []string // Scopes every bearer token must carry
+	RBACPolicyFile       string   // Policy mapping client identities to allowed tools and namespaces
+	ClientCertificates   bool     // Accept verified TLS client certificates as identities (set when mutual TLS is enabled)
+	ProfileSelection     bool     // HTTP clients may select CONFIG_FILE profiles without an RBAC policy
+}
+
+// ProfilesSelectable reports whether clients of a transport may select
+// CONFIG_FILE profiles per call or per session. Profiles carry the operator's
+// credentials, so over HTTP that needs an RBAC policy deciding who may use
+// which profile, or an explicit ALLOW_PROFILE_SELECTION=true.
+func (c *AuthConfig) ProfilesSelectable(http bool) bool {
+	return !http || c.RBACPolicyFile != "" || c.ProfileSelection
+}
+
+// Enabled reports whether any inbound authentication method is configured.
+func (c *AuthConfig) Enabled() bool {
+	return c.APIKeysFile != "" || c.JWKSFile != "" || c.ClientCertificates
+}
+
+func LoadAuthConfig() *AuthConfig {
+	return &AuthConfig{
+		APIKeysFile:          os.Getenv("AUTH_API_KEYS_FILE"),
+		JWKSFile:             os.Getenv("AUTH_JWKS_FILE"),
+		Issuer:               os.Getenv("AUTH_ISSUER"),
+		Audience:             os.Getenv("AUTH_AUDIENCE"),
+		ResourceURL:          os.Getenv("AUTH_RESOURCE_URL"),
+		AuthorizationServers: splitList(os.Getenv("AUTH_AUTHORIZATION_SERVERS")),
+		RequiredScopes:       splitList(os.Getenv("AUTH_REQUIRED_SCOPES")),
+		RBACPolicyFile:       os.Getenv("RBAC_POLICY_FILE"),
+		ProfileSelection:     os.Getenv("ALLOW_PROFILE_SELECTION") == "true",
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheConfig controls the cache of upstream GET responses.
+type CacheConfig struct {
+	TTL           time.Duration            // How long a response is served without asking the API; 0 turns the cache off, whatever TTLs says
+	TTLs          map[string]time.Duration // TTL by tool name, overriding TTL; 0 turns caching off for the tool
+	Dir           string                   // Directory responses are kept in across restarts; empty keeps them in memory
+	MaxEntries    int                      // Responses kept in memory; the oldest are dropped beyond it
+	PruneInterval time.Duration            // How often responses that can no longer be served or revalidated are dropped; 0 leaves them until MaxEntries pushes them out
+}
+
+// DefaultCacheConfig returns the settings used when no environment variable
+// overrides them. Single tags and image summaries are read repeatedly while
+// an agent works on a repository, so they are kept a little longer.
+func DefaultCacheConfig() *CacheConfig {
+	return &CacheConfig{
+		TTL: time.Minute,
+		TTLs: map[string]time.Duration{
+			"get_v2_namespaces_namespace_repositories_repository_tags_tag":       5 * time.Minute,
+			"get_v2_namespaces_namespace_repositories_repository_images-summary": 5 * time.Minute,
+			"get_v2_orgs_name_settings":                                          5 * time.Minute,
+		},
+		MaxEntries:    1000,
+		PruneInterval: 5 * time.Minute,
+	}
+}
+
+// LoadCacheConfig reads CACHE_TTL, CACHE_TTLS ("tool=duration,..."),
+// CACHE_DIR, CACHE_MAX_ENTRIES and CACHE_PRUNE_INTERVAL.
+func LoadCacheConfig() (*CacheConfig, error) {
+	cfg := DefaultCacheConfig()
+	if v := os.Getenv("CACHE_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid CACHE_TTL %q", v)
+		}
+		cfg.TTL = d
+	}
+	if v := os.Getenv("CACHE_TTLS"); v != "" {
+		for _, entry := range strings.Split(v, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			tool, value, ok := strings.Cut(entry, "=")
+			tool = strings.TrimSpace(tool)
+			d, err := time.ParseDuration(strings.TrimSpace(value))
+			if !ok || tool == "" || err != nil || d < 0 {
+				return nil, fmt.Errorf("invalid CACHE_TTLS entry %q; expected tool=duration", entry)
+			}
+			cfg.TTLs[tool] = d
+		}
+	}
+	cfg.Dir = os.Getenv("CACHE_DIR")
+	if v := os.Getenv("CACHE_MAX_ENTRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid CACHE_MAX_ENTRIES %q", v)
+		}
+		cfg.MaxEntries = n
+	}
+	if v := os.Getenv("CACHE_PRUNE_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid CACHE_PRUNE_INTERVAL %q", v)
+		}
+		cfg.PruneInterval = d
+	}
+	return cfg, nil
+}
@@ -3,6 +3,12 @@ package config
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/dockercreds"
 )
 
 type APIConfig struct {
@@ -11,38 +17,193 @@ type APIConfig struct {
 	APIKey      string // For API key authentication
 	BasicAuth   string // For basic authentication
 	Port        string // For server port configuration
+
+	DockerConfig string // Docker config.json whose credentials for BaseURL's registry are used to log in
+	SessionLogin bool   // A successful login tool call becomes the MCP session's bearer credential
+
+	UnixSocket     string      // Listen on this Unix domain socket instead of TCP
+	UnixSocketMode os.FileMode // File permissions of UnixSocket
+
+	AllowedNamespaces    []string // Namespace/org globs tool calls may address; empty allows all
+	PinnedNamespaces     []string // Server-wide globs from ALLOWED_NAMESPACES that every profile is narrowed to
+	AllowedBaseURLs      []string // Upstream base URLs requests may target
+	AllowPrivateNetworks bool     // Permit upstream hosts resolving to private or link-local addresses
+	AllowedOrigins       []string // Browser origins accepted by the HTTP transports; empty allows loopback only
+
+	Upstream UpstreamConfig // TLS and proxy settings for connections to the API
+
+	Timezone *time.Location // Zone for time arguments without an offset and for timestamps in results; nil means UTC
+
+	Profile      string                // Name of an explicitly selected profile; empty for the server default
+	Tools        []string              // Tool or group names that may be called; empty allows all
+	ExcludeTools []string              // Tool or group names that may not be called
+	Profiles     map[string]*APIConfig // Named profiles from CONFIG_FILE, selectable per call or HTTP session
 }
 
+// UpstreamConfig customizes how connections to the API are made. It is set by
+// the server operator only, never from HTTP request headers.
+type UpstreamConfig struct {
+	CAFile         string `yaml:"ca_file"`          // PEM bundle trusted in addition to the system roots, e.g. a TLS-inspecting proxy's CA
+	ClientCertFile string `yaml:"client_cert_file"` // PEM client certificate presented to the API for mutual TLS
+	ClientKeyFile  string `yaml:"client_key_file"`  // PEM private key for ClientCertFile
+	HTTPProxy      string `yaml:"http_proxy"`       // Proxy for http:// API URLs
+	HTTPSProxy     string `yaml:"https_proxy"`      // Proxy for https:// API URLs
+	NoProxy        string `yaml:"no_proxy"`         // Comma separated hosts, domains and CIDRs reached directly
+}
+
+// DefaultAllowedBaseURL is the only upstream permitted when ALLOWED_BASE_URLS is unset.
+const DefaultAllowedBaseURL = "https://hub.docker.com"
+
+// LoadAPIConfig builds the server's default configuration from the
+// environment and, when CONFIG_FILE is set, the profiles it defines. Values
+// set in the environment take precedence over the selected profile (PROFILE,
+// else the file's default_profile).
 func LoadAPIConfig() (*APIConfig, error) {
 	// Check port environment variable (both uppercase and lowercase)
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = os.Getenv("port")
 	}
-	
-	baseURL := os.Getenv("API_BASE_URL")
-	
-	// Check transport environment variable (both uppercase and lowercase)
-	transport := os.Getenv("TRANSPORT")
-	if transport == "" {
-		transport = os.Getenv("transport")
-	}
-	
-	// For STDIO mode (transport is not "http"/"HTTP"/"https"/"HTTPS"), API_BASE_URL is required from environment
-	if transport != "http" && transport != "HTTP" && transport != "https" && transport != "HTTPS" && baseURL == "" {
+
+	socketMode := os.FileMode(0660)
+	if mode := os.Getenv("UNIX_SOCKET_MODE"); mode != "" {
+		parsed, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UNIX_SOCKET_MODE %q: %v", mode, err)
+		}
+		socketMode = os.FileMode(parsed)
+	}
+
+	envUpstream := UpstreamConfig{
+		CAFile:         os.Getenv("UPSTREAM_CA_FILE"),
+		ClientCertFile: os.Getenv("UPSTREAM_CLIENT_CERT_FILE"),
+		ClientKeyFile:  os.Getenv("UPSTREAM_CLIENT_KEY_FILE"),
+		HTTPProxy:      os.Getenv("UPSTREAM_HTTP_PROXY"),
+		HTTPSProxy:     os.Getenv("UPSTREAM_HTTPS_PROXY"),
+		NoProxy:        os.Getenv("UPSTREAM_NO_PROXY"),
+	}
+
+	var timezone *time.Location
+	if name := os.Getenv("TIMEZONE"); name != "" {
+		var err error
+		if timezone, err = time.LoadLocation(name); err != nil {
+			return nil, fmt.Errorf("invalid TIMEZONE %q: %v", name, err)
+		}
+	}
+
+	// Server-wide settings shared by every profile
+	shared := APIConfig{
+		Port:                 port,
+		UnixSocket:           os.Getenv("UNIX_SOCKET"),
+		UnixSocketMode:       socketMode,
+		AllowPrivateNetworks: os.Getenv("ALLOW_PRIVATE_NETWORKS") == "true",
+		AllowedOrigins:       splitList(os.Getenv("ALLOWED_ORIGINS")),
+		Upstream:             envUpstream,
+		SessionLogin:         os.Getenv("SESSION_LOGIN") == "true",
+		Timezone:             timezone,
+		// Shared by every profile, so a profile's own list can only narrow it
+		PinnedNamespaces: splitList(os.Getenv("ALLOWED_NAMESPACES")),
+	}
+
+	cfg := shared
+	var trustedBaseURLs []string
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		file, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Profiles = make(map[string]*APIConfig, len(file.Profiles))
+		for _, name := range file.ProfileNames() {
+			p := shared
+			if err := file.Profiles[name].apply(name, &p); err != nil {
+				return nil, fmt.Errorf("config file %s: %w", path, err)
+			}
+			cfg.Profiles[name] = &p
+			trustedBaseURLs = append(trustedBaseURLs, p.BaseURL)
+		}
+
+		selected := os.Getenv("PROFILE")
+		if selected == "" {
+			selected = file.DefaultProfile
+		}
+		if selected != "" {
+			p, ok := cfg.Profiles[selected]
+			if !ok {
+				return nil, fmt.Errorf("PROFILE %q is not defined in %s", selected, path)
+			}
+			cfg.BaseURL = p.BaseURL
+			cfg.BearerToken, cfg.APIKey, cfg.BasicAuth, cfg.DockerConfig = p.BearerToken, p.APIKey, p.BasicAuth, p.DockerConfig
+			cfg.AllowedNamespaces = p.AllowedNamespaces
+			cfg.Tools, cfg.ExcludeTools = p.Tools, p.ExcludeTools
+			// The environment wins over the file for the selected profile
+			cfg.Upstream = file.Profiles[selected].Upstream.override(envUpstream)
+		}
+	}
+
+	// Environment values override the selected profile
+	if baseURL := os.Getenv("API_BASE_URL"); baseURL != "" {
+		cfg.BaseURL = baseURL
+	}
+	if os.Getenv("BEARER_TOKEN") != "" || os.Getenv("API_KEY") != "" || os.Getenv("BASIC_AUTH") != "" {
+		cfg.BearerToken = os.Getenv("BEARER_TOKEN")
+		cfg.APIKey = os.Getenv("API_KEY")
+		cfg.BasicAuth = os.Getenv("BASIC_AUTH")
+		cfg.DockerConfig = ""
+	}
+	if os.Getenv("DOCKER_CREDENTIALS") == "true" {
+		cfg.DockerConfig = dockercreds.DefaultPath()
+	}
+	// Unknown transports are reported by main; treat them as STDIO here
+	transport, _ := TransportFromEnv()
+
+	// For STDIO mode, API_BASE_URL is required from environment or profile
+	if !transport.HTTP() && cfg.BaseURL == "" {
 		return nil, fmt.Errorf("API_BASE_URL environment variable not set")
 	}
-	
-	// For HTTP/HTTPS mode (transport is "http"/"HTTP"/"https"/"HTTPS"), API_BASE_URL comes from headers
+
+	// For HTTP based modes, API_BASE_URL comes from headers
 	// so we don't require it from environment variables
 
-	return &APIConfig{
-		BaseURL:     baseURL,
-		BearerToken: os.Getenv("BEARER_TOKEN"),
-		APIKey:      os.Getenv("API_KEY"),
-		BasicAuth:   os.Getenv("BASIC_AUTH"),
-		Port:        port,
-	}, nil
+	allowedBaseURLs := splitList(os.Getenv("ALLOWED_BASE_URLS"))
+	if len(allowedBaseURLs) == 0 {
+		allowedBaseURLs = []string{DefaultAllowedBaseURL}
+	}
+	// Base URLs set by the operator in the environment or config file are trusted as is
+	if cfg.BaseURL != "" {
+		allowedBaseURLs = append(allowedBaseURLs, cfg.BaseURL)
+	}
+	for _, u := range trustedBaseURLs {
+		if u != "" {
+			allowedBaseURLs = append(allowedBaseURLs, u)
+		}
+	}
+	cfg.AllowedBaseURLs = allowedBaseURLs
+	for _, p := range cfg.Profiles {
+		p.AllowedBaseURLs = allowedBaseURLs
+	}
+
+	return &cfg, nil
 }
 
+// ProfileConfig returns the named profile, or an error listing the known ones.
+func (c *APIConfig) ProfileConfig(name string) (*APIConfig, error) {
+	if p, ok := c.Profiles[name]; ok {
+		return p, nil
+	}
+	if len(c.Profiles) == 0 {
+		return nil, fmt.Errorf("unknown profile %q: no CONFIG_FILE profiles are configured", name)
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for n := range c.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(names, ", "))
+}
 
+// splitList splits a comma or whitespace separated environment value.
+func splitList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadAPIConfigPinsNamespaces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := `default_profile: personal
+profiles:
+  personal:
+    base_url: https://hub.docker.com
+    credentials: {type: bearer, value: token}
+  org-bot:
+    base_url: https://hub.docker.com
+    credentials: {type: bearer, value: token}
+    allowed_namespaces: ["*"]
+`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("ALLOWED_NAMESPACES", "acme, acme-*")
+
+	cfg, err := LoadAPIConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"acme", "acme-*"}
+	if !reflect.DeepEqual(cfg.PinnedNamespaces, want) {
+		t.Errorf("default configuration: got %v, want %v", cfg.PinnedNamespaces, want)
+	}
+	for name, p := range cfg.Profiles {
+		if !reflect.DeepEqual(p.PinnedNamespaces, want) {
+			t.Errorf("profile %s: got %v, want %v", name, p.PinnedNamespaces, want)
+		}
+	}
+	// The profile's own list is kept; the guard requires both to match
+	if got := cfg.Profiles["org-bot"].AllowedNamespaces; !reflect.DeepEqual(got, []string{"*"}) {
+		t.Errorf("org-bot allowed_namespaces: got %v", got)
+	}
+}
+
+func TestProfilesSelectable(t *testing.T) {
+	tests := []struct {
+		cfg  AuthConfig
+		http bool
+		want bool
+	}{
+		{AuthConfig{}, false, true},
+		{AuthConfig{}, true, false},
+		{AuthConfig{RBACPolicyFile: "rbac.yaml"}, true, true},
+		{AuthConfig{ProfileSelection: true}, true, true},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.ProfilesSelectable(tt.http); got != tt.want {
+			t.Errorf("%+v.ProfilesSelectable(%v) = %v, want %v", tt.cfg, tt.http, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package config
+
+import "context"
+
+type contextKey struct{}
+
+// WithAPIConfig returns a copy of ctx that carries cfg as the effective API configuration.
+func WithAPIConfig(ctx context.Context, cfg *APIConfig) context.Context {
+	return context.WithValue(ctx, contextKey{}, cfg)
+}
+
+// FromContext returns the API configuration stored in ctx by WithAPIConfig.
+// When ctx carries none (STDIO mode), fallback is returned instead.
+func FromContext(ctx context.Context, fallback *APIConfig) *APIConfig {
+	if cfg, ok := ctx.Value(contextKey{}).(*APIConfig); ok && cfg != nil {
+		return cfg
+	}
+	return fallback
+}
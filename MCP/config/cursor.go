@@ -0,0 +1,35 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// CursorConfig controls the pagination cursors tools hand out.
+type CursorConfig struct {
+	Key []byte        // AES-256 key cursors are sealed with; empty uses a key that lasts until restart
+	TTL time.Duration // How long a cursor can be used
+}
+
+// LoadCursorConfig reads CURSOR_KEY (base64 of a 32 byte key) and CURSOR_TTL.
+func LoadCursorConfig() (*CursorConfig, error) {
+	cfg := &CursorConfig{TTL: time.Hour}
+	if v := os.Getenv("CURSOR_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid CURSOR_TTL %q", v)
+		}
+		cfg.TTL = d
+	}
+	if v := os.Getenv("CURSOR_KEY"); v != "" {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("CURSOR_KEY must be base64 of 32 bytes")
+		}
+		cfg.Key = key
+	}
+	return cfg, nil
+}
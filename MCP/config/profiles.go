@@ -0,0 +1,148 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/docker-hub-api/mcp-server/dockercreds"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the format of the CONFIG_FILE read by LoadAPIConfig.
+//
+//	default_profile: personal
+//	profiles:
+//	  personal:
+//	    base_url: https://hub.docker.com
+//	    credentials: {type: bearer, env: HUB_TOKEN}
+//	  org-bot:
+//	    base_url: https://hub.docker.com
+//	    credentials: {type: basic, file: /run/secrets/hub-bot}
+//	    allowed_namespaces: ["acme"]
+//	    tools: [read]
+type File struct {
+	DefaultProfile string                 `yaml:"default_profile"`
+	Profiles       map[string]FileProfile `yaml:"profiles"`
+}
+
+// FileProfile describes one named Hub account or environment.
+type FileProfile struct {
+	BaseURL           string           `yaml:"base_url"`
+	Credentials       CredentialSource `yaml:"credentials"`
+	AllowedNamespaces []string         `yaml:"allowed_namespaces"`
+	Tools             []string         `yaml:"tools"`         // Tool or group names this profile may call; empty allows all
+	ExcludeTools      []string         `yaml:"exclude_tools"` // Tool or group names removed from Tools
+	Upstream          UpstreamConfig   `yaml:"upstream"`      // Overrides of the UPSTREAM_* settings
+}
+
+// CredentialSource says where a profile's credential comes from. Exactly one
+// of Env, File or Value is used, in that order.
+type CredentialSource struct {
+	Type  string `yaml:"type"`  // "bearer", "basic", "api_key" or "docker" (File is then a Docker config.json)
+	Env   string `yaml:"env"`   // Environment variable holding the credential
+	File  string `yaml:"file"`  // File holding the credential; surrounding whitespace is trimmed
+	Value string `yaml:"value"` // Inline credential, discouraged outside of testing
+}
+
+// LoadFile reads and parses a profile file.
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if f.DefaultProfile != "" {
+		if _, ok := f.Profiles[f.DefaultProfile]; !ok {
+			return nil, fmt.Errorf("config file %s: default_profile %q is not defined", path, f.DefaultProfile)
+		}
+	}
+	return &f, nil
+}
+
+// ProfileNames returns the profile names in sorted order.
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply copies p's settings into cfg, resolving its credential.
+func (p FileProfile) apply(name string, cfg *APIConfig) error {
+	cfg.Profile = name
+	cfg.BaseURL = p.BaseURL
+	cfg.AllowedNamespaces = p.AllowedNamespaces
+	cfg.Tools = p.Tools
+	cfg.ExcludeTools = p.ExcludeTools
+	cfg.Upstream = cfg.Upstream.override(p.Upstream)
+	cfg.BearerToken, cfg.APIKey, cfg.BasicAuth, cfg.DockerConfig = "", "", "", ""
+
+	if strings.ToLower(p.Credentials.Type) == "docker" {
+		cfg.DockerConfig = p.Credentials.File
+		if cfg.DockerConfig == "" {
+			cfg.DockerConfig = dockercreds.DefaultPath()
+		}
+		return nil
+	}
+	secret, err := p.Credentials.resolve()
+	if err != nil {
+		return fmt.Errorf("profile %q: %w", name, err)
+	}
+	switch strings.ToLower(p.Credentials.Type) {
+	case "bearer":
+		cfg.BearerToken = secret
+	case "basic":
+		cfg.BasicAuth = secret
+	case "api_key":
+		cfg.APIKey = secret
+	case "":
+		if secret != "" {
+			return fmt.Errorf("profile %q: credentials need a type", name)
+		}
+	default:
+		return fmt.Errorf("profile %q: unknown credential type %q", name, p.Credentials.Type)
+	}
+	return nil
+}
+
+func (c CredentialSource) resolve() (string, error) {
+	switch {
+	case c.Env != "":
+		value := os.Getenv(c.Env)
+		if value == "" {
+			return "", fmt.Errorf("credential environment variable %s is not set", c.Env)
+		}
+		return value, nil
+	case c.File != "":
+		data, err := os.ReadFile(c.File)
+		if err != nil {
+			return "", fmt.Errorf("failed to read credential file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return c.Value, nil
+	}
+}
+
+// override returns u with the non-empty fields of o applied.
+func (u UpstreamConfig) override(o UpstreamConfig) UpstreamConfig {
+	set := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	set(&u.CAFile, o.CAFile)
+	set(&u.ClientCertFile, o.ClientCertFile)
+	set(&u.ClientKeyFile, o.ClientKeyFile)
+	set(&u.HTTPProxy, o.HTTPProxy)
+	set(&u.HTTPSProxy, o.HTTPSProxy)
+	set(&u.NoProxy, o.NoProxy)
+	return u
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ResultsConfig controls the store large tool results are written to and
+// read back from as MCP resources.
+type ResultsConfig struct {
+	TTL         time.Duration // How long a stored result can be read
+	InlineBytes int           // Results longer than this are stored and linked instead of returned; 0 never stores them
+	ChunkBytes  int           // Largest chunk resources/read returns at once
+	MaxBytes    int           // Total size of stored results; the oldest are dropped beyond it
+}
+
+// DefaultResultsConfig returns the settings used when no environment
+// variable overrides them.
+func DefaultResultsConfig() *ResultsConfig {
+	return &ResultsConfig{
+		TTL:         30 * time.Minute,
+		InlineBytes: 64 << 10,
+		ChunkBytes:  64 << 10,
+		MaxBytes:    256 << 20,
+	}
+}
+
+// LoadResultsConfig reads RESULT_TTL, RESULT_INLINE_BYTES, RESULT_CHUNK_BYTES
+// and RESULT_STORE_BYTES.
+func LoadResultsConfig() (*ResultsConfig, error) {
+	cfg := DefaultResultsConfig()
+	if v := os.Getenv("RESULT_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid RESULT_TTL %q", v)
+		}
+		cfg.TTL = d
+	}
+	sizes := []struct {
+		env      string
+		value    *int
+		allowOff bool
+	}{
+		{"RESULT_INLINE_BYTES", &cfg.InlineBytes, true},
+		{"RESULT_CHUNK_BYTES", &cfg.ChunkBytes, false},
+		{"RESULT_STORE_BYTES", &cfg.MaxBytes, false},
+	}
+	for _, size := range sizes {
+		v := os.Getenv(size.env)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n == 0 && !size.allowOff {
+			return nil, fmt.Errorf("invalid %s %q", size.env, v)
+		}
+		*size.value = n
+	}
+	return cfg, nil
+}
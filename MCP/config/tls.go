@@ -0,0 +1,81 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// TLSConfig controls serving the HTTP transports over TLS.
+type TLSConfig struct {
+	CertFile       string             // PEM server certificate chain
+	KeyFile        string             // PEM private key for CertFile
+	ClientCAFile   string             // PEM CA bundle used to verify client certificates; enables mutual TLS
+	ClientAuth     tls.ClientAuthType // Whether client certificates are requested and required
+	MinVersion     uint16             // Lowest accepted protocol version
+	CipherSuites   []uint16           // TLS 1.2 cipher suites; nil uses the Go defaults
+	ReloadInterval time.Duration      // How often certificate files are checked for changes; 0 disables polling
+}
+
+// LoadTLSConfig reads CERT_FILE, KEY_FILE and the TLS_* policy variables.
+func LoadTLSConfig() (*TLSConfig, error) {
+	cfg := &TLSConfig{
+		CertFile:       os.Getenv("CERT_FILE"),
+		KeyFile:        os.Getenv("KEY_FILE"),
+		ClientCAFile:   os.Getenv("TLS_CLIENT_CA_FILE"),
+		MinVersion:     tls.VersionTLS12,
+		ReloadInterval: 30 * time.Second,
+	}
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, fmt.Errorf("CERT_FILE and KEY_FILE environment variables are required for HTTPS mode")
+	}
+
+	clientAuth := strings.ToLower(os.Getenv("TLS_CLIENT_AUTH"))
+	switch {
+	case clientAuth == "" && cfg.ClientCAFile != "", clientAuth == "require":
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	case clientAuth == "request", clientAuth == "optional":
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	case clientAuth == "", clientAuth == "none":
+		cfg.ClientAuth = tls.NoClientCert
+	default:
+		return nil, fmt.Errorf("invalid TLS_CLIENT_AUTH %q: expected none, optional or require", clientAuth)
+	}
+	if cfg.ClientAuth != tls.NoClientCert && cfg.ClientCAFile == "" {
+		return nil, fmt.Errorf("TLS_CLIENT_AUTH=%s requires TLS_CLIENT_CA_FILE", clientAuth)
+	}
+
+	switch v := os.Getenv("TLS_MIN_VERSION"); v {
+	case "", "1.2":
+	case "1.3":
+		cfg.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("invalid TLS_MIN_VERSION %q: expected 1.2 or 1.3", v)
+	}
+
+	if names := splitList(os.Getenv("TLS_CIPHER_SUITES")); len(names) > 0 {
+		// Only suites Go considers secure are accepted
+		known := make(map[string]uint16)
+		for _, s := range tls.CipherSuites() {
+			known[s.Name] = s.ID
+		}
+		for _, name := range names {
+			id, ok := known[name]
+			if !ok {
+				return nil, fmt.Errorf("unsupported TLS_CIPHER_SUITES entry %q", name)
+			}
+			cfg.CipherSuites = append(cfg.CipherSuites, id)
+		}
+	}
+
+	if v := os.Getenv("TLS_RELOAD_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid TLS_RELOAD_INTERVAL %q", v)
+		}
+		cfg.ReloadInterval = d
+	}
+	return cfg, nil
+}
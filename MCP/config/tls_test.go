@@ -0,0 +1,92 @@
+package config
+
+import (
+	"crypto/tls"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadTLSConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            map[string]string
+		wantClientAuth tls.ClientAuthType
+		wantMin        uint16
+		wantSuites     []uint16
+		wantReload     time.Duration
+		wantErr        string
+	}{
+		{name: "defaults", wantClientAuth: tls.NoClientCert},
+		{name: "no certificate", env: map[string]string{"CERT_FILE": ""}, wantErr: "CERT_FILE and KEY_FILE"},
+		{name: "no key", env: map[string]string{"KEY_FILE": ""}, wantErr: "CERT_FILE and KEY_FILE"},
+		{name: "CA file alone requires certificates", env: map[string]string{"TLS_CLIENT_CA_FILE": "ca.pem"}, wantClientAuth: tls.RequireAndVerifyClientCert},
+		{name: "require", env: map[string]string{"TLS_CLIENT_AUTH": "require", "TLS_CLIENT_CA_FILE": "ca.pem"}, wantClientAuth: tls.RequireAndVerifyClientCert},
+		{name: "optional", env: map[string]string{"TLS_CLIENT_AUTH": "optional", "TLS_CLIENT_CA_FILE": "ca.pem"}, wantClientAuth: tls.VerifyClientCertIfGiven},
+		{name: "request", env: map[string]string{"TLS_CLIENT_AUTH": "Request", "TLS_CLIENT_CA_FILE": "ca.pem"}, wantClientAuth: tls.VerifyClientCertIfGiven},
+		{name: "none with a CA file", env: map[string]string{"TLS_CLIENT_AUTH": "none", "TLS_CLIENT_CA_FILE": "ca.pem"}, wantClientAuth: tls.NoClientCert},
+		{name: "require without a CA file", env: map[string]string{"TLS_CLIENT_AUTH": "require"}, wantErr: "TLS_CLIENT_AUTH=require requires TLS_CLIENT_CA_FILE"},
+		{name: "optional without a CA file", env: map[string]string{"TLS_CLIENT_AUTH": "optional"}, wantErr: "requires TLS_CLIENT_CA_FILE"},
+		{name: "unknown client auth", env: map[string]string{"TLS_CLIENT_AUTH": "always", "TLS_CLIENT_CA_FILE": "ca.pem"}, wantErr: "invalid TLS_CLIENT_AUTH"},
+		{name: "TLS 1.2", env: map[string]string{"TLS_MIN_VERSION": "1.2"}, wantClientAuth: tls.NoClientCert},
+		{name: "TLS 1.3", env: map[string]string{"TLS_MIN_VERSION": "1.3"}, wantClientAuth: tls.NoClientCert, wantMin: tls.VersionTLS13},
+		{name: "TLS 1.1", env: map[string]string{"TLS_MIN_VERSION": "1.1"}, wantErr: "invalid TLS_MIN_VERSION"},
+		{name: "TLS version by name", env: map[string]string{"TLS_MIN_VERSION": "TLS1.3"}, wantErr: "invalid TLS_MIN_VERSION"},
+		{
+			name:           "secure cipher suites",
+			env:            map[string]string{"TLS_CIPHER_SUITES": "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"},
+			wantClientAuth: tls.NoClientCert,
+			wantSuites:     []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256},
+		},
+		{name: "insecure cipher suite", env: map[string]string{"TLS_CIPHER_SUITES": "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_RSA_WITH_RC4_128_SHA"}, wantErr: `unsupported TLS_CIPHER_SUITES entry "TLS_RSA_WITH_RC4_128_SHA"`},
+		{name: "CBC cipher suite", env: map[string]string{"TLS_CIPHER_SUITES": "TLS_RSA_WITH_AES_128_CBC_SHA256"}, wantErr: "unsupported TLS_CIPHER_SUITES entry"},
+		{name: "unknown cipher suite", env: map[string]string{"TLS_CIPHER_SUITES": "AES"}, wantErr: "unsupported TLS_CIPHER_SUITES entry"},
+		{name: "reload interval", env: map[string]string{"TLS_RELOAD_INTERVAL": "1m"}, wantClientAuth: tls.NoClientCert, wantReload: time.Minute},
+		{name: "reload disabled", env: map[string]string{"TLS_RELOAD_INTERVAL": "0"}, wantClientAuth: tls.NoClientCert, wantReload: -1},
+		{name: "negative reload interval", env: map[string]string{"TLS_RELOAD_INTERVAL": "-1s"}, wantErr: "invalid TLS_RELOAD_INTERVAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{
+				"CERT_FILE": "cert.pem", "KEY_FILE": "key.pem", "TLS_CLIENT_CA_FILE": "", "TLS_CLIENT_AUTH": "",
+				"TLS_MIN_VERSION": "", "TLS_CIPHER_SUITES": "", "TLS_RELOAD_INTERVAL": "",
+			}
+			for name, value := range tt.env {
+				env[name] = value
+			}
+			for name, value := range env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := LoadTLSConfig()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantMin := tt.wantMin
+			if wantMin == 0 {
+				wantMin = tls.VersionTLS12
+			}
+			wantReload := tt.wantReload
+			switch wantReload {
+			case 0:
+				wantReload = 30 * time.Second
+			case -1:
+				wantReload = 0
+			}
+			if cfg.ClientAuth != tt.wantClientAuth || cfg.MinVersion != wantMin || cfg.ReloadInterval != wantReload {
+				t.Errorf("ClientAuth = %v, MinVersion = %x, ReloadInterval = %v; want %v, %x, %v",
+					cfg.ClientAuth, cfg.MinVersion, cfg.ReloadInterval, tt.wantClientAuth, wantMin, wantReload)
+			}
+			if !reflect.DeepEqual(cfg.CipherSuites, tt.wantSuites) {
+				t.Errorf("CipherSuites = %v, want %v", cfg.CipherSuites, tt.wantSuites)
+			}
+		})
+	}
+}
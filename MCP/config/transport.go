@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Transport describes the MCP transports selected by the TRANSPORT variable.
+// The zero value is STDIO.
+type Transport struct {
+	Streamable bool // Streamable HTTP at /mcp
+	SSE        bool // Legacy HTTP+SSE at /sse and /message
+	TLS        bool // Serve the HTTP transports over TLS
+}
+
+// TransportFromEnv reads TRANSPORT (or lowercase transport).
+func TransportFromEnv() (Transport, error) {
+	transport := os.Getenv("TRANSPORT")
+	if transport == "" {
+		transport = os.Getenv("transport")
+	}
+	return ParseTransport(transport)
+}
+
+// ParseTransport parses a TRANSPORT value made of "+" separated, case
+// insensitive parts: "stdio", "http", "https", "sse" and "tls". For example
+// "https" serves streamable HTTP over TLS, "sse+tls" serves only the legacy SSE
+// transport over TLS, and "http+sse" serves both transports on one port.
+func ParseTransport(value string) (Transport, error) {
+	var t Transport
+	if value == "" {
+		return t, nil
+	}
+	for _, part := range strings.Split(strings.ToLower(value), "+") {
+		switch part {
+		case "stdio":
+		case "http":
+			t.Streamable = true
+		case "https":
+			t.Streamable, t.TLS = true, true
+		case "sse":
+			t.SSE = true
+		case "tls":
+			t.TLS = true
+		default:
+			return t, fmt.Errorf("unknown transport %q", part)
+		}
+	}
+	if t.TLS && !t.HTTP() {
+		return t, fmt.Errorf("transport %q enables TLS without an HTTP transport", value)
+	}
+	return t, nil
+}
+
+// HTTP reports whether any HTTP based transport is enabled.
+func (t Transport) HTTP() bool {
+	return t.Streamable || t.SSE
+}
+
+func (t Transport) String() string {
+	if !t.HTTP() {
+		return "STDIO"
+	}
+	var parts []string
+	if t.Streamable {
+		if t.TLS {
+			parts = append(parts, "HTTPS")
+		} else {
+			parts = append(parts, "HTTP")
+		}
+	}
+	if t.SSE {
+		if t.TLS && !t.Streamable {
+			parts = append(parts, "SSE+TLS")
+		} else {
+			parts = append(parts, "SSE")
+		}
+	}
+	return strings.Join(parts, "+")
+}
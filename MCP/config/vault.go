@@ -0,0 +1,72 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VaultConfig controls where secrets returned by tools are kept and how they
+// may be revealed.
+type VaultConfig struct {
+	File        string        // Encrypted file the vault is persisted to; empty keeps secrets in memory only
+	Key         []byte        // AES-256 key for File
+	AllowReveal bool          // reveal_secret may return plaintext in the tool result
+	ExportDir   string        // Directory reveal_secret may write secrets to
+	TTL         time.Duration // How long a secret is kept after it was stored; 0 keeps it until deleted
+	MaxSecrets  int           // Secrets kept; the oldest are dropped beyond it
+}
+
+// DefaultVaultConfig returns the settings used when no environment variable
+// overrides them.
+func DefaultVaultConfig() *VaultConfig {
+	return &VaultConfig{TTL: 24 * time.Hour, MaxSecrets: 1000}
+}
+
+// LoadVaultConfig reads VAULT_FILE, VAULT_KEY or VAULT_KEY_FILE (base64 of a
+// 32 byte key), VAULT_ALLOW_REVEAL, VAULT_EXPORT_DIR, VAULT_TTL and
+// VAULT_MAX_SECRETS.
+func LoadVaultConfig() (*VaultConfig, error) {
+	cfg := DefaultVaultConfig()
+	cfg.File = os.Getenv("VAULT_FILE")
+	cfg.AllowReveal = os.Getenv("VAULT_ALLOW_REVEAL") == "true"
+	cfg.ExportDir = os.Getenv("VAULT_EXPORT_DIR")
+	if v := os.Getenv("VAULT_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid VAULT_TTL %q", v)
+		}
+		cfg.TTL = d
+	}
+	if v := os.Getenv("VAULT_MAX_SECRETS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid VAULT_MAX_SECRETS %q", v)
+		}
+		cfg.MaxSecrets = n
+	}
+	if cfg.File == "" {
+		return cfg, nil
+	}
+
+	encoded := os.Getenv("VAULT_KEY")
+	if path := os.Getenv("VAULT_KEY_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read VAULT_KEY_FILE: %w", err)
+		}
+		encoded = string(data)
+	}
+	if encoded == "" {
+		return nil, fmt.Errorf("VAULT_FILE requires VAULT_KEY or VAULT_KEY_FILE")
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("vault key is not valid base64: %v", err)
+	}
+	cfg.Key = key
+	return cfg, nil
+}
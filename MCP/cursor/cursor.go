@@ -0,0 +1,316 @@
+// Package cursor replaces the upstream next and previous page URLs of
+// paginated tools with opaque cursors. A cursor seals the tool, its
+// arguments and a page with AES-GCM, so it can be neither read nor altered,
+// and it expires. A cursor only opens for the client and session it was
+// returned to.
+package cursor
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/auth"
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/formatter"
+	"github.com/docker-hub-api/mcp-server/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Argument and result fields of paginated tools.
+const (
+	Argument      = "cursor"
+	NextField     = "next_cursor"
+	PreviousField = "previous_cursor"
+)
+
+// Fields are the result fields cursors are returned in.
+var Fields = []string{NextField, PreviousField}
+
+var (
+	errInvalid = errors.New("invalid cursor; pass next_cursor or previous_cursor exactly as returned")
+	errExpired = errors.New("cursor expired; repeat the call without cursor to start over")
+)
+
+// Default is the sealer used by the middlewares. main replaces it with one
+// using the configured key and TTL.
+var Default = mustNew(&config.CursorConfig{TTL: time.Hour})
+
+// Sealer seals and opens cursors.
+type Sealer struct {
+	aead cipher.AEAD
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// New returns a sealer using cfg.Key, or a random key when it is empty.
+func New(cfg *config.CursorConfig) (*Sealer, error) {
+	key := cfg.Key
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{aead: aead, ttl: cfg.TTL, now: time.Now}, nil
+}
+
+func mustNew(cfg *config.CursorConfig) *Sealer {
+	s, err := New(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// position is what a cursor holds.
+type position struct {
+	Tool    string         `json:"t"`
+	Args    map[string]any `json:"a"`
+	Page    int64          `json:"p"`
+	Expires int64          `json:"x"`
+}
+
+// Seal returns a cursor for page of tool called with args by the client and
+// session of ctx.
+func (s *Sealer) Seal(ctx context.Context, tool string, args map[string]any, page int64) string {
+	plain, _ := json.Marshal(position{Tool: tool, Args: args, Page: page, Expires: s.now().Add(s.ttl).Unix()})
+	nonce := make([]byte, s.aead.NonceSize())
+	rand.Read(nonce)
+	return base64.RawURLEncoding.EncodeToString(s.aead.Seal(nonce, nonce, plain, additionalData(ctx, tool)))
+}
+
+// Open returns the arguments and page a cursor for tool was sealed with.
+func (s *Sealer) Open(ctx context.Context, tool, cursor string) (map[string]any, int64, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(cursor))
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return nil, 0, errInvalid
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, ciphertext, additionalData(ctx, tool))
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w; it may belong to another tool, client or session", errInvalid)
+	}
+	var p position
+	decoder := json.NewDecoder(strings.NewReader(string(plain)))
+	decoder.UseNumber()
+	if decoder.Decode(&p) != nil || p.Tool != tool {
+		return nil, 0, errInvalid
+	}
+	if s.now().Unix() > p.Expires {
+		return nil, 0, errExpired
+	}
+	return p.Args, p.Page, nil
+}
+
+// additionalData is authenticated with a cursor, so it only opens for the
+// same tool, inbound identity and MCP session. Calls without inbound
+// authentication (STDIO or an open HTTP port) share the empty identity.
+func additionalData(ctx context.Context, tool string) []byte {
+	var principal, session string
+	if p, ok := auth.PrincipalFromContext(ctx); ok {
+		principal = p.ID()
+	}
+	if s := server.ClientSessionFromContext(ctx); s != nil {
+		session = s.SessionID()
+	}
+	return []byte(strings.Join([]string{tool, principal, session}, "\x00"))
+}
+
+// paginated reports whether tool takes a page argument, and the page size
+// the API uses when none is given.
+func paginated(tool string) (bool, int64) {
+	op, ok := validate.Lookup(tool)
+	if !ok || op.Args.Properties["page"] == nil {
+		return false, 0
+	}
+	size := int64(0)
+	if s := op.Args.Properties["page_size"]; s != nil {
+		size, _ = toInt(s.Default)
+	}
+	return true, size
+}
+
+// keeps reports whether cursors for tool keep the named argument: the spec
+// describes it and it is not the page. Arguments that only change how the
+// result is shown, such as format, are not kept.
+func keeps(tool, name string) bool {
+	op, _ := validate.Lookup(tool)
+	_, described := op.Args.Properties[name]
+	return described && name != "page"
+}
+
+// isTime reports whether the named argument of tool is a date-time. Cursors
+// keep the time a relative expression such as "7d" stood for when the
+// listing started, so a repeated expression is not compared with it.
+func isTime(tool, name string) bool {
+	op, _ := validate.Lookup(tool)
+	s := op.Args.Properties[name]
+	return s != nil && s.Format == "date-time"
+}
+
+// sealedArgs returns the arguments of a call its cursors keep.
+func sealedArgs(tool string, args map[string]any) map[string]any {
+	kept := make(map[string]any)
+	for name, value := range args {
+		if keeps(tool, name) {
+			kept[name] = value
+		}
+	}
+	return kept
+}
+
+// Describe adds the cursor argument to paginated tools.
+func Describe(tool *mcp.Tool) {
+	if ok, _ := paginated(tool.Name); !ok {
+		return
+	}
+	tool.InputSchema.Properties[Argument] = map[string]any{
+		"type":        "string",
+		"description": fmt.Sprintf("Continues a listing: %s or %s of an earlier result of this tool. It sets the page and the other arguments, which may be repeated unchanged. Cursors expire after %s.", NextField, PreviousField, Default.ttl),
+	}
+}
+
+// Expand is a middleware that replaces the cursor argument of paginated
+// tools with the arguments and page it was sealed with. It runs before
+// validation, RBAC and the namespace guard, so a cursor's arguments are
+// checked like any others.
+func Expand(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, _ := request.Params.Arguments.(map[string]any)
+		value := args[Argument]
+		if ok, _ := paginated(request.Params.Name); !ok || value == nil {
+			return next(ctx, request)
+		}
+		cursor, isString := value.(string)
+		if !isString {
+			return mcp.NewToolResultError(errInvalid.Error()), nil
+		}
+		sealed, page, err := Default.Open(ctx, request.Params.Name, cursor)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		expanded := make(map[string]any, len(args)+len(sealed))
+		for name, v := range args {
+			if name == Argument {
+				continue
+			}
+			if name == "page" {
+				return mcp.NewToolResultError("cursor sets the page; drop page or drop cursor"), nil
+			}
+			if kept, isSealed := sealed[name]; isSealed && !same(kept, v) && !isTime(request.Params.Name, name) || !isSealed && keeps(request.Params.Name, name) {
+				return mcp.NewToolResultError(fmt.Sprintf("%s differs from the call the cursor continues; drop it or drop cursor to start a new listing", name)), nil
+			}
+			expanded[name] = v
+		}
+		for name, v := range sealed {
+			expanded[name] = v
+		}
+		expanded["page"] = page
+		request.Params.Arguments = expanded
+		return next(ctx, request)
+	}
+}
+
+// Rewrite is a middleware that replaces the next and previous page URLs
+// of paginated results with cursors. Results without them, such as audit
+// logs, get a next cursor when their page is full.
+func Rewrite(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		ok, defaultSize := paginated(request.Params.Name)
+		if err != nil || result == nil || result.IsError || !ok {
+			return result, err
+		}
+		args, _ := request.Params.Arguments.(map[string]any)
+		page, hasPage := toInt(args["page"])
+		if !hasPage || page < 1 {
+			page = 1
+		}
+		size, hasSize := toInt(args["page_size"])
+		if !hasSize {
+			size = defaultSize
+		}
+		sealed := sealedArgs(request.Params.Name, args)
+		for i, content := range result.Content {
+			text, isText := content.(mcp.TextContent)
+			if !isText {
+				continue
+			}
+			decoder := json.NewDecoder(strings.NewReader(text.Text))
+			decoder.UseNumber()
+			var obj map[string]any
+			if decoder.Decode(&obj) != nil {
+				continue
+			}
+			var hasNext, hasPrevious bool
+			if _, linked := obj["next"]; linked {
+				hasNext, hasPrevious = present(obj["next"]), present(obj["previous"])
+				delete(obj, "next")
+				delete(obj, "previous")
+			} else {
+				list, _ := formatter.List(obj)
+				hasNext, hasPrevious = size > 0 && int64(len(list)) >= size, page > 1
+			}
+			if hasNext {
+				obj[NextField] = Default.Seal(ctx, request.Params.Name, sealed, page+1)
+			}
+			if hasPrevious && page > 1 {
+				obj[PreviousField] = Default.Seal(ctx, request.Params.Name, sealed, page-1)
+			}
+			rewritten, err := json.MarshalIndent(obj, "", "  ")
+			if err != nil {
+				continue
+			}
+			text.Text = string(rewritten)
+			result.Content[i] = text
+			break
+		}
+		return result, nil
+	}
+}
+
+func present(v any) bool {
+	s, ok := v.(string)
+	return ok && s != ""
+}
+
+// same compares argument values loosely, so "25" matches 25.
+func same(a, b any) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func toInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(n), 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
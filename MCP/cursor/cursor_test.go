@@ -0,0 +1,362 @@
+package cursor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/auth"
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const tagsTool = "get_v2_namespaces_namespace_repositories_repository_tags"
+
+type session string
+
+func (s session) Initialize()                                         {}
+func (s session) Initialized() bool                                   { return true }
+func (s session) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s session) SessionID() string                                   { return string(s) }
+
+func callContext(method, subject, sessionID string) context.Context {
+	ctx := context.Background()
+	if subject != "" {
+		ctx = auth.WithPrincipal(ctx, &auth.Principal{Method: method, Subject: subject})
+	}
+	if sessionID != "" {
+		ctx = server.NewMCPServer("test", "1").WithContext(ctx, session(sessionID))
+	}
+	return ctx
+}
+
+func TestSealerOpen(t *testing.T) {
+	s, err := New(&config.CursorConfig{TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	sealedIn := callContext("bearer", "alice", "s1")
+	cursor := s.Seal(sealedIn, tagsTool, map[string]any{"namespace": "acme"}, 2)
+
+	tampered := []byte(cursor)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		tool   string
+		cursor string
+		later  time.Duration
+		err    error
+	}{
+		{name: "same client and session", ctx: sealedIn, tool: tagsTool, cursor: cursor},
+		{name: "another tool", ctx: sealedIn, tool: "get_v2_access-tokens", cursor: cursor, err: errInvalid},
+		{name: "another subject", ctx: callContext("bearer", "mallory", "s1"), tool: tagsTool, cursor: cursor, err: errInvalid},
+		{name: "same subject by another method", ctx: callContext("api_key", "alice", "s1"), tool: tagsTool, cursor: cursor, err: errInvalid},
+		{name: "another session", ctx: callContext("bearer", "alice", "s2"), tool: tagsTool, cursor: cursor, err: errInvalid},
+		{name: "unauthenticated", ctx: callContext("", "", "s1"), tool: tagsTool, cursor: cursor, err: errInvalid},
+		{name: "altered", ctx: sealedIn, tool: tagsTool, cursor: string(tampered), err: errInvalid},
+		{name: "not base64", ctx: sealedIn, tool: tagsTool, cursor: "not a cursor!", err: errInvalid},
+		{name: "too short", ctx: sealedIn, tool: tagsTool, cursor: base64.RawURLEncoding.EncodeToString([]byte("x")), err: errInvalid},
+		{name: "expired", ctx: sealedIn, tool: tagsTool, cursor: cursor, later: 2 * time.Hour, err: errExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.now = func() time.Time { return now.Add(tt.later) }
+			args, page, err := s.Open(tt.ctx, tt.tool, tt.cursor)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err == nil && (page != 2 || args["namespace"] != "acme") {
+				t.Errorf("got %v page %d", args, page)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	ctx := callContext("bearer", "alice", "s1")
+	cursor := Default.Seal(ctx, tagsTool, map[string]any{"namespace": "acme", "repository": "web"}, 3)
+	tests := []struct {
+		name string
+		args map[string]any
+		want map[string]any // Arguments the next handler sees
+		err  string         // Part of the tool error
+	}{
+		{
+			name: "cursor alone",
+			args: map[string]any{"cursor": cursor},
+			want: map[string]any{"namespace": "acme", "repository": "web", "page": int64(3)},
+		},
+		{
+			name: "repeated arguments and presentation",
+			args: map[string]any{"cursor": cursor, "namespace": "acme", "format": "csv"},
+			want: map[string]any{"namespace": "acme", "repository": "web", "format": "csv", "page": int64(3)},
+		},
+		{name: "differing argument", args: map[string]any{"cursor": cursor, "namespace": "other"}, err: "namespace differs"},
+		{name: "new argument", args: map[string]any{"cursor": cursor, "page_size": 50.0}, err: "page_size differs"},
+		{name: "page", args: map[string]any{"cursor": cursor, "page": 1.0}, err: "drop page"},
+		{name: "not a string", args: map[string]any{"cursor": 7.0}, err: "invalid cursor"},
+		{
+			name: "no cursor",
+			args: map[string]any{"namespace": "acme", "repository": "web"},
+			want: map[string]any{"namespace": "acme", "repository": "web"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]any
+			handler := Expand(func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				got, _ = request.Params.Arguments.(map[string]any)
+				return mcp.NewToolResultText("ok"), nil
+			})
+			var req mcp.CallToolRequest
+			req.Params.Name = tagsTool
+			req.Params.Arguments = tt.args
+			res, err := handler(ctx, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.err != "" {
+				if !res.IsError || !strings.Contains(res.Content[0].(mcp.TextContent).Text, tt.err) {
+					t.Fatalf("got %+v, want error %q", res.Content, tt.err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+const auditTool = "get_v2_auditlogs_account"
+
+// rewrite runs Rewrite over a handler returning body for a call of tool with args.
+func rewrite(t *testing.T, ctx context.Context, tool string, args map[string]any, result *mcp.CallToolResult) map[string]any {
+	t.Helper()
+	handler := Rewrite(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return result, nil
+	})
+	var req mcp.CallToolRequest
+	req.Params.Name = tool
+	req.Params.Arguments = args
+	res, err := handler(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &obj); err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestRewrite(t *testing.T) {
+	ctx := callContext("bearer", "alice", "s1")
+	tags := map[string]any{"namespace": "acme", "repository": "web"}
+	withPage := func(args map[string]any, page int) map[string]any {
+		copied := map[string]any{"page": page}
+		for name, v := range args {
+			copied[name] = v
+		}
+		return copied
+	}
+	tests := []struct {
+		name         string
+		tool         string
+		args         map[string]any
+		body         string
+		isError      bool
+		wantNext     int64 // Page the next cursor opens to; 0 means none
+		wantPrevious int64
+		wantKept     []string // Fields the result keeps as they were
+	}{
+		{
+			name:     "first page",
+			tool:     tagsTool,
+			args:     tags,
+			body:     `{"count": 30, "next": "https://hub.docker.com/v2/namespaces/acme/repositories/web/tags?page=2", "previous": null, "results": []}`,
+			wantNext: 2,
+			wantKept: []string{"count", "results"},
+		},
+		{
+			name:         "middle page",
+			tool:         tagsTool,
+			args:         withPage(tags, 2),
+			body:         `{"count": 30, "next": "https://hub.docker.com/v2/namespaces/acme/repositories/web/tags?page=3", "previous": "https://hub.docker.com/v2/namespaces/acme/repositories/web/tags?page=1", "results": []}`,
+			wantNext:     3,
+			wantPrevious: 1,
+		},
+		{
+			name:         "last page",
+			tool:         tagsTool,
+			args:         withPage(tags, 3),
+			body:         `{"count": 30, "next": null, "previous": "https://hub.docker.com/v2/namespaces/acme/repositories/web/tags?page=2", "results": []}`,
+			wantPrevious: 2,
+		},
+		{
+			name: "only page",
+			tool: tagsTool,
+			args: tags,
+			body: `{"count": 1, "next": "", "previous": null, "results": [{"name": "latest"}]}`,
+		},
+		{
+			name:     "full audit log page",
+			tool:     auditTool,
+			args:     map[string]any{"account": "acme", "page_size": 2},
+			body:     `{"logs": [{"action": "repo.create"}, {"action": "repo.delete"}]}`,
+			wantNext: 2,
+			wantKept: []string{"logs"},
+		},
+		{
+			name:         "full audit log page after the first",
+			tool:         auditTool,
+			args:         map[string]any{"account": "acme", "page_size": 2, "page": 4},
+			body:         `{"logs": [{"action": "repo.create"}, {"action": "repo.delete"}]}`,
+			wantNext:     5,
+			wantPrevious: 3,
+		},
+		{
+			name:         "partial audit log page",
+			tool:         auditTool,
+			args:         map[string]any{"account": "acme", "page_size": 2, "page": 2},
+			body:         `{"logs": [{"action": "repo.create"}]}`,
+			wantPrevious: 1,
+		},
+		{
+			name:     "audit log page of the default size",
+			tool:     auditTool,
+			args:     map[string]any{"account": "acme"},
+			body:     `{"logs": [` + strings.Repeat(`{},`, 24) + `{}]}`,
+			wantNext: 2,
+		},
+		{
+			name:     "error result",
+			tool:     tagsTool,
+			args:     tags,
+			body:     `{"next": "https://hub.docker.com/v2/namespaces/acme/repositories/web/tags?page=2"}`,
+			isError:  true,
+			wantKept: []string{"next"},
+		},
+		{
+			name:     "not paginated",
+			tool:     "get_v2_namespaces_namespace_repositories_repository",
+			args:     tags,
+			body:     `{"name": "web", "next": "https://hub.docker.com/elsewhere"}`,
+			wantKept: []string{"name", "next"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := mcp.NewToolResultText(tt.body)
+			result.IsError = tt.isError
+			var before map[string]any
+			json.Unmarshal([]byte(tt.body), &before)
+			got := rewrite(t, ctx, tt.tool, tt.args, result)
+
+			for _, name := range tt.wantKept {
+				if !reflect.DeepEqual(got[name], before[name]) {
+					t.Errorf("%s = %v, want %v", name, got[name], before[name])
+				}
+			}
+			if tt.isError || tt.tool != tagsTool && tt.tool != auditTool {
+				if len(got) != len(before) {
+					t.Errorf("result changed: %v", got)
+				}
+				return
+			}
+			if _, ok := got["next"]; ok {
+				t.Errorf("upstream next URL kept: %v", got["next"])
+			}
+			if _, ok := got["previous"]; ok {
+				t.Errorf("upstream previous URL kept: %v", got["previous"])
+			}
+			for field, want := range map[string]int64{NextField: tt.wantNext, PreviousField: tt.wantPrevious} {
+				cursor, ok := got[field].(string)
+				if ok != (want != 0) {
+					t.Errorf("%s = %v, want a cursor: %v", field, got[field], want != 0)
+					continue
+				}
+				if !ok {
+					continue
+				}
+				args, page, err := Default.Open(ctx, tt.tool, cursor)
+				if err != nil || page != want || args["page"] != nil || args["account"] != tt.args["account"] || args["namespace"] != tt.args["namespace"] {
+					t.Errorf("%s opens to %v page %d (%v), want page %d", field, args, page, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRewriteExpandRoundTrip(t *testing.T) {
+	ctx := callContext("bearer", "alice", "s1")
+	first := rewrite(t, ctx, tagsTool, map[string]any{"namespace": "acme", "repository": "web", "page_size": 10, "format": "csv"},
+		mcp.NewToolResultText(`{"count": 30, "next": "https://hub.docker.com/v2/namespaces/acme/repositories/web/tags?page=2", "results": []}`))
+	next, _ := first[NextField].(string)
+	if next == "" {
+		t.Fatalf("no next cursor in %v", first)
+	}
+	tampered := []byte(next)
+	tampered[len(tampered)/2] ^= 1
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		args map[string]any
+		want map[string]any // Arguments the tool sees
+		err  string         // Part of the tool error
+	}{
+		{
+			name: "cursor continues the listing",
+			ctx:  ctx,
+			args: map[string]any{Argument: next},
+			want: map[string]any{"namespace": "acme", "repository": "web", "page_size": json.Number("10"), "page": int64(2)},
+		},
+		{
+			name: "presentation may change",
+			ctx:  ctx,
+			args: map[string]any{Argument: next, "format": "json", "namespace": "acme"},
+			want: map[string]any{"namespace": "acme", "repository": "web", "page_size": json.Number("10"), "format": "json", "page": int64(2)},
+		},
+		{name: "tampered namespace", ctx: ctx, args: map[string]any{Argument: next, "namespace": "victim"}, err: "namespace differs"},
+		{name: "tampered page size", ctx: ctx, args: map[string]any{Argument: next, "page_size": 100}, err: "page_size differs"},
+		{name: "tampered page", ctx: ctx, args: map[string]any{Argument: next, "page": 9}, err: "drop page"},
+		{name: "tampered cursor", ctx: ctx, args: map[string]any{Argument: string(tampered)}, err: "invalid cursor"},
+		{name: "another client", ctx: callContext("bearer", "mallory", "s1"), args: map[string]any{Argument: next}, err: "invalid cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]any
+			handler := Expand(func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				got, _ = request.Params.Arguments.(map[string]any)
+				return mcp.NewToolResultText("ok"), nil
+			})
+			var req mcp.CallToolRequest
+			req.Params.Name = tagsTool
+			req.Params.Arguments = tt.args
+			res, err := handler(tt.ctx, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.err != "" {
+				if !res.IsError || !strings.Contains(res.Content[0].(mcp.TextContent).Text, tt.err) || got != nil {
+					t.Fatalf("got %+v with arguments %v, want error %q", res.Content, got, tt.err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
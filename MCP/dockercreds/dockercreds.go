@@ -0,0 +1,180 @@
+// Package dockercreds reads registry credentials saved by "docker login":
+// inline "auths" entries of config.json and the credsStore/credHelpers
+// programs (docker-credential-*) that speak the credential helper protocol.
+package dockercreds
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// HubRegistry is the key "docker login" uses for Docker Hub.
+const HubRegistry = "https://index.docker.io/v1/"
+
+// ErrNotFound is returned when no credential is stored for a registry.
+var ErrNotFound = errors.New("no docker credentials found")
+
+// Credential is a username and secret (password or personal access token).
+type Credential struct {
+	Username string
+	Secret   string
+}
+
+// File is the subset of config.json used for credentials.
+type File struct {
+	Auths       map[string]AuthEntry `json:"auths"`
+	CredsStore  string               `json:"credsStore"`
+	CredHelpers map[string]string    `json:"credHelpers"`
+}
+
+// AuthEntry is one "auths" entry.
+type AuthEntry struct {
+	Auth          string `json:"auth"` // base64 "username:password"
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identitytoken"`
+}
+
+// Store looks up credentials in a Docker config file.
+type Store struct {
+	Path string // Path of config.json
+
+	// Exec builds the command for a credential helper. It defaults to
+	// exec.CommandContext and can be replaced, e.g. to run a fake helper.
+	Exec func(ctx context.Context, name string, arg ...string) *exec.Cmd
+}
+
+// DefaultPath returns $DOCKER_CONFIG/config.json, or ~/.docker/config.json.
+func DefaultPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// RegistryForBaseURL returns the config.json key "docker login" stores the
+// credentials of the API at baseURL under: HubRegistry for Docker Hub, the
+// host name otherwise.
+func RegistryForBaseURL(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return baseURL
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "docker.io" || host == "docker.com" || strings.HasSuffix(host, ".docker.io") || strings.HasSuffix(host, ".docker.com") {
+		return HubRegistry
+	}
+	return u.Host
+}
+
+// Get returns the credential for registry. A registry-specific helper in
+// credHelpers wins over credsStore, which wins over inline "auths" entries.
+func (s *Store) Get(ctx context.Context, registry string) (Credential, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return Credential{}, fmt.Errorf("failed to read docker config: %w", err)
+	}
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Credential{}, fmt.Errorf("failed to parse docker config %s: %w", s.Path, err)
+	}
+
+	keys := candidateKeys(registry)
+	for _, key := range keys {
+		if helper := file.CredHelpers[key]; helper != "" {
+			return s.helperGet(ctx, helper, key)
+		}
+	}
+	if file.CredsStore != "" {
+		for _, key := range keys {
+			cred, err := s.helperGet(ctx, file.CredsStore, key)
+			if !errors.Is(err, ErrNotFound) {
+				return cred, err
+			}
+		}
+	}
+	for _, key := range keys {
+		if entry, ok := file.Auths[key]; ok {
+			return entry.credential(key)
+		}
+	}
+	return Credential{}, fmt.Errorf("%w for %s in %s", ErrNotFound, registry, s.Path)
+}
+
+// candidateKeys lists the spellings a registry may be stored under.
+func candidateKeys(registry string) []string {
+	if registry == HubRegistry {
+		return []string{HubRegistry, "index.docker.io", "docker.io", "registry-1.docker.io"}
+	}
+	return []string{registry, "https://" + registry, "http://" + registry}
+}
+
+func (e AuthEntry) credential(key string) (Credential, error) {
+	if e.IdentityToken != "" {
+		return Credential{}, fmt.Errorf("docker credentials for %s are an identity token, which the Hub login API does not accept; log in with a personal access token", key)
+	}
+	if e.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(e.Auth)
+		if err != nil {
+			return Credential{}, fmt.Errorf("invalid auth entry for %s: %w", key, err)
+		}
+		user, secret, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return Credential{}, fmt.Errorf("invalid auth entry for %s", key)
+		}
+		return Credential{Username: user, Secret: secret}, nil
+	}
+	if e.Username != "" {
+		return Credential{Username: e.Username, Secret: e.Password}, nil
+	}
+	return Credential{}, fmt.Errorf("%w for %s", ErrNotFound, key)
+}
+
+// helperGet runs "docker-credential-<helper> get" with the server URL on
+// stdin and reads {"ServerURL", "Username", "Secret"} from stdout.
+func (s *Store) helperGet(ctx context.Context, helper, serverURL string) (Credential, error) {
+	command := s.Exec
+	if command == nil {
+		command = exec.CommandContext
+	}
+	cmd := command(ctx, "docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		// Helpers report a missing entry on stdout with a non-zero exit
+		msg := strings.TrimSpace(stdout.String() + " " + stderr.String())
+		if strings.Contains(msg, "credentials not found") {
+			return Credential{}, fmt.Errorf("%w for %s in docker-credential-%s", ErrNotFound, serverURL, helper)
+		}
+		if msg != "" {
+			err = fmt.Errorf("%v: %s", err, msg)
+		}
+		return Credential{}, fmt.Errorf("docker-credential-%s get failed: %w", helper, err)
+	}
+
+	var out struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return Credential{}, fmt.Errorf("invalid docker-credential-%s output: %w", helper, err)
+	}
+	if out.Username == "<token>" {
+		return Credential{}, fmt.Errorf("docker-credential-%s returned an identity token for %s, which the Hub login API does not accept; log in with a personal access token", helper, serverURL)
+	}
+	return Credential{Username: out.Username, Secret: out.Secret}, nil
+}
@@ -0,0 +1,204 @@
+package dockercreds
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHelperProcess is not a test: it is the fake docker-credential-* helper
+// run by fakeHelper. Helpers are named after how they answer.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) != 3 || args[2] != "get" {
+		fmt.Fprintf(os.Stderr, "unexpected arguments %q", args)
+		os.Exit(2)
+	}
+	serverURL, _ := io.ReadAll(os.Stdin)
+	switch strings.TrimPrefix(args[1], "docker-credential-") {
+	case "desktop":
+		if string(serverURL) != HubRegistry {
+			fmt.Print("credentials not found in native keychain")
+			os.Exit(1)
+		}
+		fmt.Printf(`{"ServerURL":%q,"Username":"alice","Secret":"dckr_pat_desktop"}`, serverURL)
+	case "empty":
+		fmt.Print("credentials not found in native keychain")
+		os.Exit(1)
+	case "locked":
+		fmt.Fprint(os.Stderr, "error getting credentials - err: exit status 1, out: keychain is locked")
+		os.Exit(1)
+	case "garbled":
+		fmt.Print("not json")
+	case "identity":
+		fmt.Printf(`{"ServerURL":%q,"Username":"<token>","Secret":"refresh"}`, serverURL)
+	}
+	os.Exit(0)
+}
+
+// fakeHelper runs this test binary as the credential helper.
+func fakeHelper(ctx context.Context, name string, arg ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, os.Args[0], append([]string{"-test.run=TestHelperProcess", "--", name}, arg...)...)
+	cmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=1")
+	return cmd
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStoreGet(t *testing.T) {
+	inline := base64.StdEncoding.EncodeToString([]byte("bob:dckr_pat_inline"))
+	tests := []struct {
+		name     string
+		config   string
+		registry string
+		want     Credential
+		err      error
+		message  string // Part of the error
+		fromPath bool   // Run helpers from PATH instead of the fake
+	}{
+		{
+			name:     "credsStore",
+			config:   `{"credsStore":"desktop"}`,
+			registry: HubRegistry,
+			want:     Credential{Username: "alice", Secret: "dckr_pat_desktop"},
+		},
+		{
+			name:     "credHelpers wins over credsStore",
+			config:   `{"credsStore":"empty","credHelpers":{"https://index.docker.io/v1/":"desktop"},"auths":{"index.docker.io":{"auth":"` + inline + `"}}}`,
+			registry: HubRegistry,
+			want:     Credential{Username: "alice", Secret: "dckr_pat_desktop"},
+		},
+		{
+			name:     "credHelpers without the entry does not fall back",
+			config:   `{"credHelpers":{"index.docker.io":"empty"},"auths":{"index.docker.io":{"auth":"` + inline + `"}}}`,
+			registry: HubRegistry,
+			err:      ErrNotFound,
+			message:  "in docker-credential-empty",
+		},
+		{
+			name:     "credsStore without the entry falls back to auths",
+			config:   `{"credsStore":"empty","auths":{"https://index.docker.io/v1/":{"auth":"` + inline + `"}}}`,
+			registry: HubRegistry,
+			want:     Credential{Username: "bob", Secret: "dckr_pat_inline"},
+		},
+		{
+			name:     "not found",
+			config:   `{"credsStore":"empty"}`,
+			registry: HubRegistry,
+			err:      ErrNotFound,
+			message:  "no docker credentials found",
+		},
+		{
+			name:     "helper error",
+			config:   `{"credsStore":"locked"}`,
+			registry: HubRegistry,
+			message:  "docker-credential-locked get failed: exit status 1: error getting credentials - err: exit status 1, out: keychain is locked",
+		},
+		{
+			name:     "helper output that is not JSON",
+			config:   `{"credsStore":"garbled"}`,
+			registry: HubRegistry,
+			message:  "invalid docker-credential-garbled output",
+		},
+		{
+			name:     "identity token from a helper",
+			config:   `{"credsStore":"identity"}`,
+			registry: HubRegistry,
+			message:  "identity token",
+		},
+		{
+			name:     "missing helper",
+			config:   `{"credsStore":"does-not-exist-anywhere"}`,
+			registry: HubRegistry,
+			message:  "docker-credential-does-not-exist-anywhere get failed",
+			fromPath: true,
+		},
+		{
+			name:     "inline username and password for another registry",
+			config:   `{"auths":{"https://hub.example.com":{"username":"carol","password":"secret"}}}`,
+			registry: "hub.example.com",
+			want:     Credential{Username: "carol", Secret: "secret"},
+		},
+		{
+			name:     "inline identity token",
+			config:   `{"auths":{"docker.io":{"identitytoken":"refresh"}}}`,
+			registry: HubRegistry,
+			message:  "identity token",
+		},
+		{
+			name:     "malformed auth",
+			config:   `{"auths":{"docker.io":{"auth":"bm9jb2xvbg=="}}}`,
+			registry: HubRegistry,
+			message:  "invalid auth entry",
+		},
+		{
+			name:     "not JSON",
+			config:   `{`,
+			registry: HubRegistry,
+			message:  "failed to parse docker config",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Store{Path: writeConfig(t, tt.config), Exec: fakeHelper}
+			if tt.fromPath {
+				s.Exec = nil
+			}
+			got, err := s.Get(context.Background(), tt.registry)
+			if tt.err == nil && tt.message == "" {
+				if err != nil || got != tt.want {
+					t.Fatalf("got %+v, %v; want %+v", got, err, tt.want)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("got %+v, want an error", got)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestRegistryForBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{"https://hub.docker.com", HubRegistry},
+		{"https://HUB.docker.com:443/v2", HubRegistry},
+		{"https://index.docker.io", HubRegistry},
+		{"https://hub.example.com:8443", "hub.example.com:8443"},
+		{"https://docker.com.example.org", "docker.com.example.org"},
+		{"not a url", "not a url"},
+	}
+	for _, tt := range tests {
+		if got := RegistryForBaseURL(tt.baseURL); got != tt.want {
+			t.Errorf("RegistryForBaseURL(%q) = %q, want %q", tt.baseURL, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+)
+
+// envFlags maps command line flags to the environment variables they override.
+var envFlags = []struct {
+	name, env, usage string
+}{
+	{"config", "CONFIG_FILE", "YAML file with named profiles"},
+	{"profile", "PROFILE", "profile from the config file to use by default"},
+	{"transport", "TRANSPORT", "stdio, http, https, sse or a + separated combination"},
+	{"port", "PORT", "port for the HTTP transports"},
+	{"unix-socket", "UNIX_SOCKET", "Unix domain socket for the HTTP transports"},
+	{"base-url", "API_BASE_URL", "base URL of the Docker Hub API"},
+	{"allowed-namespaces", "ALLOWED_NAMESPACES", "comma separated namespaces tool calls may address"},
+}
+
+// parseFlags applies command line flags on top of the environment, so that
+// flags take precedence over environment variables, which in turn take
+// precedence over the config file.
+func parseFlags() {
+	values := make([]*string, len(envFlags))
+	for i, f := range envFlags {
+		values[i] = flag.String(f.name, "", f.usage+" (overrides "+f.env+")")
+	}
+	flag.Parse()
+
+	flag.Visit(func(set *flag.Flag) {
+		for i, f := range envFlags {
+			if f.name == set.Name {
+				if err := os.Setenv(f.env, *values[i]); err != nil {
+					log.Fatalf("Failed to apply -%s: %v", f.name, err)
+				}
+			}
+		}
+	})
+}
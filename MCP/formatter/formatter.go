@@ -0,0 +1,134 @@
+// Package formatter renders JSON tool results as JSON, YAML, a markdown
+// table, CSV or NDJSON.
+//
+// Tables are built from a result's list of items, such as the "results" of
+// a page of tags, with one row per item. Nested fields are flattened:
+//   - objects become dotted columns, e.g. "restricted_images.enabled"
+//   - arrays of scalars become one cell, e.g. "repo:read, repo:write"
+//   - arrays of objects become "[]" columns holding the values of every
+//     element, e.g. "images[].architecture" = "amd64, arm64"
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats a result can be rendered in.
+const (
+	JSON     = "json"
+	YAML     = "yaml"
+	Markdown = "markdown"
+	CSV      = "csv"
+	NDJSON   = "ndjson"
+)
+
+// Formats lists the accepted format names, the default first.
+var Formats = []string{JSON, YAML, Markdown, CSV, NDJSON}
+
+// MIMEType returns the media type of results rendered in format.
+func MIMEType(format string) string {
+	switch format {
+	case YAML:
+		return "application/yaml"
+	case Markdown:
+		return "text/markdown"
+	case CSV:
+		return "text/csv"
+	case NDJSON:
+		return "application/x-ndjson"
+	}
+	return "application/json"
+}
+
+// Render renders v, a result decoded from JSON, in format. Tables show the
+// default columns of model (see ModelFor) when the items have any of them,
+// and every flattened field otherwise or when model is empty.
+func Render(v any, format, model string) ([]byte, error) {
+	switch format {
+	case "", JSON:
+		return json.MarshalIndent(v, "", "  ")
+	case YAML:
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(plain(v)); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case Markdown:
+		return markdown(v, model), nil
+	case CSV:
+		return csvTable(v, model)
+	case NDJSON:
+		return ndjson(v)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// List returns the list a result is about: v itself when it is an array,
+// otherwise its longest array field, such as "results" or "logs".
+func List(v any) ([]any, string) {
+	switch value := v.(type) {
+	case []any:
+		return value, ""
+	case map[string]any:
+		var list []any
+		var name string
+		for key, field := range value {
+			if items, ok := field.([]any); ok && (list == nil || len(items) > len(list) || len(items) == len(list) && key < name) {
+				list, name = items, key
+			}
+		}
+		return list, name
+	}
+	return nil, ""
+}
+
+// ndjson renders each item of the list of v as one line of JSON, keeping
+// every field; a result without a list becomes a single line.
+func ndjson(v any) ([]byte, error) {
+	list, _ := List(v)
+	if list == nil {
+		list = []any{v}
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, item := range list {
+		if err := encoder.Encode(item); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// plain replaces json.Number, which YAML would quote as a string, with
+// int64 or float64.
+func plain(v any) any {
+	switch value := v.(type) {
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return n
+		}
+		if f, err := value.Float64(); err == nil {
+			return f
+		}
+		return value.String()
+	case map[string]any:
+		out := make(map[string]any, len(value))
+		for key, field := range value {
+			out[key] = plain(field)
+		}
+		return out
+	case []any:
+		out := make([]any, len(value))
+		for i, item := range value {
+			out[i] = plain(item)
+		}
+		return out
+	}
+	return v
+}
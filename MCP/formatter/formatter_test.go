@@ -0,0 +1,229 @@
+package formatter
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decode reads s keeping numbers as json.Number, as tool results are read.
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		t.Fatalf("decode %s: %v", s, err)
+	}
+	return v
+}
+
+const tags = `{
+	"count": 2,
+	"next_cursor": "c2",
+	"results": [
+		{"name": "latest", "tag_status": "active", "full_size": 12345678901, "last_updated": "2026-10-16T12:00:00Z", "last_updated_relative": "just now", "digest": "sha256:1",
+		 "images": [{"architecture": "amd64", "os": "linux"}, {"architecture": "arm64", "os": "linux"}]},
+		{"name": "v1|old", "tag_status": "inactive", "full_size": 10, "last_updated": "2025-01-01T00:00:00Z", "last_updated_relative": "1 year ago", "digest": "sha256:2",
+		 "images": []}
+	]
+}`
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		format string
+		model  string
+		want   string
+	}{
+		{
+			name:   "json",
+			in:     `{"b": 1, "a": [true]}`,
+			format: JSON,
+			want:   "{\n  \"a\": [\n    true\n  ],\n  \"b\": 1\n}",
+		},
+		{
+			name:   "yaml keeps numbers",
+			in:     `{"size": 12345678901, "ratio": 0.5, "tag": "1.0"}`,
+			format: YAML,
+			want:   "ratio: 0.5\nsize: 12345678901\ntag: \"1.0\"\n",
+		},
+		{
+			name:   "markdown with model columns",
+			in:     tags,
+			format: Markdown,
+			model:  "Tag",
+			want: "- count: 2\n- next_cursor: c2\n\n" +
+				"| name | tag_status | full_size | last_updated | last_updated_relative | images[].architecture |\n" +
+				"|---|---|---|---|---|---|\n" +
+				"| latest | active | 12345678901 | 2026-10-16T12:00:00Z | just now | amd64, arm64 |\n" +
+				"| v1\\|old | inactive | 10 | 2025-01-01T00:00:00Z | 1 year ago |  |\n",
+		},
+		{
+			name:   "markdown every column without a model",
+			in:     `[{"b": {"c": 1}, "a": ["x", "y"]}, {"d": null}]`,
+			format: Markdown,
+			want:   "| a | b.c | d |\n|---|---|---|\n| x, y | 1 |  |\n|  |  |  |\n",
+		},
+		{
+			name:   "markdown without a list",
+			in:     `{"name": "acme", "settings": {"enabled": true}}`,
+			format: Markdown,
+			want:   "| Field | Value |\n|---|---|\n| name | acme |\n| settings.enabled | true |\n",
+		},
+		{
+			name:   "markdown empty list",
+			in:     `{"count": 0, "results": []}`,
+			format: Markdown,
+			want:   "- count: 0\n\n_No results._\n",
+		},
+		{
+			name:   "csv with model columns",
+			in:     tags,
+			format: CSV,
+			model:  "Tag",
+			want: "name,tag_status,full_size,last_updated,last_updated_relative,images[].architecture\n" +
+				"latest,active,12345678901,2026-10-16T12:00:00Z,just now,\"amd64, arm64\"\n" +
+				"v1|old,inactive,10,2025-01-01T00:00:00Z,1 year ago,\n",
+		},
+		{
+			name:   "csv model absent from items",
+			in:     `[{"id": 1}]`,
+			format: CSV,
+			model:  "Tag",
+			want:   "id\n1\n",
+		},
+		{
+			name:   "csv without a list",
+			in:     `{"name": "acme", "seats": 5}`,
+			format: CSV,
+			want:   "field,value\nname,acme\nseats,5\n",
+		},
+		{
+			name:   "ndjson",
+			in:     `{"count": 2, "results": [{"name": "a"}, {"name": "b"}]}`,
+			format: NDJSON,
+			want:   "{\"name\":\"a\"}\n{\"name\":\"b\"}\n",
+		},
+		{
+			name:   "ndjson without a list",
+			in:     `{"name": "acme"}`,
+			format: NDJSON,
+			want:   "{\"name\":\"acme\"}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(decode(t, tt.in), tt.format, tt.model)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+	if _, err := Render(decode(t, `{}`), "xml", ""); err == nil {
+		t.Error("unknown format rendered")
+	}
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantKey string
+		wantLen int
+		wantNil bool
+	}{
+		{name: "array", in: `[1, 2]`, wantLen: 2},
+		{name: "longest array field", in: `{"tags": [1], "results": [1, 2, 3]}`, wantKey: "results", wantLen: 3},
+		{name: "ties by name", in: `{"b": [1], "a": [2]}`, wantKey: "a", wantLen: 1},
+		{name: "no array", in: `{"name": "acme"}`, wantNil: true},
+		{name: "scalar", in: `3`, wantNil: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, key := List(decode(t, tt.in))
+			if (list == nil) != tt.wantNil || len(list) != tt.wantLen || key != tt.wantKey {
+				t.Errorf("List = %v, %q; want %d items under %q", list, key, tt.wantLen, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestLeftOut(t *testing.T) {
+	tests := []struct {
+		format string
+		in     string
+		want   string
+	}{
+		{CSV, tags, "count: 2\nnext_cursor: c2"},
+		{NDJSON, `{"count": 1, "page": {"next": null}, "results": [{}]}`, "count: 1\npage.next: "},
+		{Markdown, tags, ""},
+		{JSON, tags, ""},
+		{CSV, `{"name": "acme"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := LeftOut(decode(t, tt.in), tt.format); got != tt.want {
+				t.Errorf("LeftOut = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		wantCells map[string]string
+		wantOrder []string
+	}{
+		{
+			name:      "objects become dotted columns",
+			in:        `{"restricted_images": {"enabled": true, "allow_official_images": false}}`,
+			wantCells: map[string]string{"restricted_images.allow_official_images": "false", "restricted_images.enabled": "true"},
+			wantOrder: []string{"restricted_images.allow_official_images", "restricted_images.enabled"},
+		},
+		{
+			name:      "arrays of scalars become one cell",
+			in:        `{"scopes": ["repo:read", "repo:write"]}`,
+			wantCells: map[string]string{"scopes": "repo:read, repo:write"},
+			wantOrder: []string{"scopes"},
+		},
+		{
+			name:      "arrays of objects become [] columns",
+			in:        `{"images": [{"architecture": "amd64", "size": 1}, {"architecture": "arm64"}]}`,
+			wantCells: map[string]string{"images[].architecture": "amd64, arm64", "images[].size": "1"},
+			wantOrder: []string{"images[].architecture", "images[].size"},
+		},
+		{
+			name:      "scalar item",
+			in:        `"latest"`,
+			wantCells: map[string]string{"value": "latest"},
+			wantOrder: []string{"value"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := flatten(decode(t, tt.in))
+			if !reflect.DeepEqual(r.cells, tt.wantCells) || !reflect.DeepEqual(r.order, tt.wantOrder) {
+				t.Errorf("got %v in order %v, want %v in order %v", r.cells, r.order, tt.wantCells, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestMIMEType(t *testing.T) {
+	for format, want := range map[string]string{
+		JSON: "application/json", YAML: "application/yaml", Markdown: "text/markdown",
+		CSV: "text/csv", NDJSON: "application/x-ndjson", "": "application/json",
+	} {
+		if got := MIMEType(format); got != want {
+			t.Errorf("MIMEType(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package formatter
+
+import "github.com/docker-hub-api/mcp-server/times"
+
+// columns are the default table columns of the models tools return, most
+// telling first. Time columns are followed by their relative sibling when
+// the result has one.
+var columns = map[string][]string{
+	"Tag":            {"name", "tag_status", "full_size", "last_updated", "tag_last_pushed", "tag_last_pulled", "last_updater_username", "images[].architecture"},
+	"Image":          {"digest", "status", "tags[].tag", "last_pushed", "last_pulled"},
+	"ImageTag":       {"tag", "is_current"},
+	"AuditLog":       {"timestamp", "action", "actor", "name", "action_description"},
+	"AuditLogAction": {"name", "label", "description"},
+	"AccessToken":    {"token_label", "uuid", "is_active", "scopes", "created_at", "last_used", "generated_by"},
+}
+
+// toolModels names the model each tool returns.
+var toolModels = map[string]string{
+	"get_v2_namespaces_namespace_repositories_repository_tags":               "Tag",
+	"get_v2_namespaces_namespace_repositories_repository_tags_tag":           "Tag",
+	"get_v2_namespaces_namespace_repositories_repository_images":             "Image",
+	"get_v2_namespaces_namespace_repositories_repository_images_digest_tags": "ImageTag",
+	"get_v2_auditlogs_account":                                               "AuditLog",
+	"get_v2_auditlogs_account_actions":                                       "AuditLogAction",
+	"get_v2_access-tokens":                                                   "AccessToken",
+	"get_v2_access-tokens_uuid":                                              "AccessToken",
+	"post_v2_access-tokens":                                                  "AccessToken",
+	"patch_v2_access-tokens_uuid":                                            "AccessToken",
+}
+
+// ModelFor returns the model the named tool returns, or "" when it has no
+// default columns.
+func ModelFor(tool string) string {
+	return toolModels[tool]
+}
+
+// defaultColumns returns the default columns of model present in rows,
+// each followed by its relative time sibling, or nil when there are none.
+func defaultColumns(model string, present map[string]bool) []string {
+	var cols []string
+	for _, col := range columns[model] {
+		if !present[col] {
+			continue
+		}
+		cols = append(cols, col)
+		if present[col+times.RelativeSuffix] {
+			cols = append(cols, col+times.RelativeSuffix)
+		}
+	}
+	return cols
+}
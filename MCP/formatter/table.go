@@ -0,0 +1,225 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// row is one flattened item: its cells by column and the columns in the
+// order they were met.
+type row struct {
+	cells map[string]string
+	order []string
+}
+
+func (r *row) set(col, value string) {
+	if _, ok := r.cells[col]; !ok {
+		r.order = append(r.order, col)
+	}
+	r.cells[col] = value
+}
+
+// flatten turns v into a row, see the package documentation.
+func flatten(v any) row {
+	r := row{cells: make(map[string]string)}
+	if _, ok := v.(map[string]any); !ok {
+		r.set("value", cell(v))
+		return r
+	}
+	flattenInto(&r, "", v)
+	return r
+}
+
+func flattenInto(r *row, prefix string, v any) {
+	switch value := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			flattenInto(r, join(prefix, key), value[key])
+		}
+	case []any:
+		var scalars []string
+		merged := row{cells: make(map[string]string)}
+		for _, item := range value {
+			if _, ok := item.(map[string]any); !ok {
+				scalars = append(scalars, cell(item))
+				continue
+			}
+			element := row{cells: make(map[string]string)}
+			flattenInto(&element, "", item)
+			for _, col := range element.order {
+				if prev, ok := merged.cells[col]; ok {
+					merged.set(col, prev+", "+element.cells[col])
+				} else {
+					merged.set(col, element.cells[col])
+				}
+			}
+		}
+		if len(merged.order) == 0 {
+			r.set(prefix, strings.Join(scalars, ", "))
+			return
+		}
+		for _, col := range merged.order {
+			r.set(prefix+"[]."+col, merged.cells[col])
+		}
+	default:
+		r.set(prefix, cell(v))
+	}
+}
+
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func cell(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// table flattens items into rows and picks their columns: the default
+// columns of model when the rows have any, otherwise every column in the
+// order met.
+func table(items []any, model string) ([]string, []row) {
+	rows := make([]row, len(items))
+	present := make(map[string]bool)
+	var all []string
+	for i, item := range items {
+		rows[i] = flatten(item)
+		for _, col := range rows[i].order {
+			if !present[col] {
+				present[col] = true
+				all = append(all, col)
+			}
+		}
+	}
+	if cols := defaultColumns(model, present); len(cols) > 0 {
+		return cols, rows
+	}
+	return all, rows
+}
+
+// fields flattens the fields of v other than its list into one row.
+func fields(v any, listKey string) row {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return row{cells: make(map[string]string)}
+	}
+	rest := make(map[string]any, len(obj))
+	for key, field := range obj {
+		if key != listKey {
+			rest[key] = field
+		}
+	}
+	return flatten(rest)
+}
+
+// LeftOut returns the fields of v outside its list, one "name: value" per
+// line, when format leaves them out of its rendering (CSV and NDJSON).
+func LeftOut(v any, format string) string {
+	if format != CSV && format != NDJSON {
+		return ""
+	}
+	list, key := List(v)
+	if list == nil {
+		return ""
+	}
+	meta := fields(v, key)
+	lines := make([]string, len(meta.order))
+	for i, col := range meta.order {
+		lines[i] = col + ": " + meta.cells[col]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdown renders the fields of v as a list and its items as a table; a
+// result without a list becomes a table of fields and values.
+func markdown(v any, model string) []byte {
+	var buf bytes.Buffer
+	list, key := List(v)
+	if list == nil {
+		cols, rows := table([]any{v}, model)
+		if len(rows) == 0 || len(cols) == 0 {
+			return []byte(cell(v))
+		}
+		buf.WriteString("| Field | Value |\n|---|---|\n")
+		for _, col := range cols {
+			fmt.Fprintf(&buf, "| %s | %s |\n", escape(col), escape(rows[0].cells[col]))
+		}
+		return buf.Bytes()
+	}
+	meta := fields(v, key)
+	for _, col := range meta.order {
+		fmt.Fprintf(&buf, "- %s: %s\n", col, meta.cells[col])
+	}
+	if len(meta.order) > 0 {
+		buf.WriteString("\n")
+	}
+	if len(list) == 0 {
+		buf.WriteString("_No results._\n")
+		return buf.Bytes()
+	}
+	cols, rows := table(list, model)
+	escaped := make([]string, len(cols))
+	for i, col := range cols {
+		escaped[i] = escape(col)
+	}
+	buf.WriteString("| " + strings.Join(escaped, " | ") + " |\n")
+	buf.WriteString("|" + strings.Repeat("---|", len(cols)) + "\n")
+	for _, r := range rows {
+		for i, col := range cols {
+			escaped[i] = escape(r.cells[col])
+		}
+		buf.WriteString("| " + strings.Join(escaped, " | ") + " |\n")
+	}
+	return buf.Bytes()
+}
+
+func escape(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// csvTable renders the items of v with a header row; a result without a
+// list becomes field and value columns.
+func csvTable(v any, model string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	list, _ := List(v)
+	if list == nil {
+		cols, rows := table([]any{v}, model)
+		w.Write([]string{"field", "value"})
+		for _, col := range cols {
+			w.Write([]string{col, rows[0].cells[col]})
+		}
+	} else {
+		cols, rows := table(list, model)
+		w.Write(cols)
+		for _, r := range rows {
+			record := make([]string, len(cols))
+			for i, col := range cols {
+				record[i] = r.cells[col]
+			}
+			w.Write(record)
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
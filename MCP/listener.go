@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+// sdListenFDsStart is the first file descriptor passed by systemd socket activation.
+const sdListenFDsStart = 3
+
+// listen returns the listener shared by the HTTP transports: a socket inherited
+// from systemd when LISTEN_FDS is set, the Unix domain socket UNIX_SOCKET, or
+// TCP on 0.0.0.0:PORT.
+func listen(cfg *config.APIConfig) (net.Listener, error) {
+	if l, err := systemdListener(); l != nil || err != nil {
+		return l, err
+	}
+	if cfg.UnixSocket != "" {
+		return unixListener(cfg.UnixSocket, cfg.UnixSocketMode)
+	}
+	if cfg.Port == "" {
+		return nil, fmt.Errorf("PORT environment variable is required for HTTP/HTTPS mode. Please set PORT, UNIX_SOCKET or use systemd socket activation")
+	}
+	return net.Listen("tcp", net.JoinHostPort("0.0.0.0", cfg.Port))
+}
+
+// systemdListener implements the sd_listen_fds(3) protocol. It returns nil, nil
+// when the process was not socket activated.
+func systemdListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, nil
+	}
+	// Do not pass the activation environment on to child processes
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	if n > 1 {
+		log.Printf("Warning: systemd passed %d sockets; only the first is used", n)
+	}
+
+	f := os.NewFile(uintptr(sdListenFDsStart), "systemd-socket")
+	defer f.Close()
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use systemd socket: %w", err)
+	}
+	return l, nil
+}
+
+// unixListener listens on path, replacing a stale socket left by a previous run.
+func unixListener(path string, mode os.FileMode) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("UNIX_SOCKET %s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to set UNIX_SOCKET permissions: %w", err)
+	}
+	return l, nil
+}
@@ -3,101 +3,166 @@ package main
 import (
 	"context"
 	"log"
-	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
 	"syscall"
 	"time"
 
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/docker-hub-api/mcp-server/auth"
+	"github.com/docker-hub-api/mcp-server/cache"
 	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/cursor"
+	"github.com/docker-hub-api/mcp-server/policy"
+	"github.com/docker-hub-api/mcp-server/results"
+	"github.com/docker-hub-api/mcp-server/shape"
+	"github.com/docker-hub-api/mcp-server/tlsutil"
+	"github.com/docker-hub-api/mcp-server/upstream"
+	"github.com/docker-hub-api/mcp-server/validate"
+	"github.com/docker-hub-api/mcp-server/vault"
 )
 
 func main() {
+	parseFlags()
+
+	transport, err := config.TransportFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid TRANSPORT: %v", err)
+	}
+
 	cfg, err := config.LoadAPIConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Check transport environment variable (both uppercase and lowercase)
-	transport := os.Getenv("TRANSPORT")
-	if transport == "" {
-		transport = os.Getenv("transport")
+	// Fail fast on unreadable upstream CA bundles, client certificates or proxy URLs
+	if _, err := upstream.Transport(cfg); err != nil {
+		log.Fatalf("Invalid upstream TLS or proxy configuration: %v", err)
+	}
+
+	// Secrets returned by tools are kept in the vault and replaced by handles
+	vaultCfg, err := config.LoadVaultConfig()
+	if err != nil {
+		log.Fatalf("Invalid vault configuration: %v", err)
+	}
+	if vault.Default, err = vault.Load(vaultCfg); err != nil {
+		log.Fatalf("Failed to open vault: %v", err)
+	}
+
+	resultsCfg, err := config.LoadResultsConfig()
+	if err != nil {
+		log.Fatalf("Invalid result store configuration: %v", err)
+	}
+	results.Stored = results.NewStore(resultsCfg)
+
+	cursorCfg, err := config.LoadCursorConfig()
+	if err != nil {
+		log.Fatalf("Invalid cursor configuration: %v", err)
+	}
+	if cursor.Default, err = cursor.New(cursorCfg); err != nil {
+		log.Fatalf("Failed to set up cursors: %v", err)
+	}
+
+	// GET responses are reused within their TTL and revalidated after it
+	cacheCfg, err := config.LoadCacheConfig()
+	if err != nil {
+		log.Fatalf("Invalid cache configuration: %v", err)
+	}
+	if cache.Default, err = cache.New(cacheCfg); err != nil {
+		log.Fatalf("Failed to set up response cache: %v", err)
 	}
+	go cache.Default.PruneEvery(cacheCfg.PruneInterval, nil)
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// HTTP/HTTPS Mode - if transport is "http", "HTTP", "https", or "HTTPS"
-	if transport == "http" || transport == "HTTP" || transport == "https" || transport == "HTTPS" {
-		port := cfg.Port
-		if port == "" {
-			log.Fatalf("PORT environment variable is required for HTTP/HTTPS mode. Please set PORT environment variable.")
+	// HTTP based modes - streamable HTTP, legacy SSE or both, optionally over TLS
+	if transport.HTTP() {
+		listener, err := listen(cfg)
+		if err != nil {
+			log.Fatalf("Failed to listen: %v", err)
 		}
+		addr := listener.Addr().String()
 
-		// Determine if HTTPS mode and normalize transport
-		isHTTPS := transport == "https" || transport == "HTTPS"
-		if isHTTPS {
-			transport = "HTTPS"
-		} else {
-			transport = "HTTP"
+		log.Printf("Running in %s mode on %s %s", transport, listener.Addr().Network(), addr)
+
+		// Tools and the MCP server are built once; handlers resolve the
+		// per-request APIConfig from the context populated below.
+		mcpSrv := createMCPServer(cfg, transport.String())
+		contextFunc := func(ctx context.Context, r *http.Request) context.Context {
+			return config.WithAPIConfig(ctx, apiConfigFromHeaders(r, cfg))
 		}
-		
-		log.Printf("Running in %s mode on port %s", transport, port)
 
-		mux := http.NewServeMux()
-		mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
-			// Read headers for dynamic config
-			apiCfg := &config.APIConfig{
-				BaseURL:     r.Header.Get("API_BASE_URL"),
-				BearerToken: r.Header.Get("BEARER_TOKEN"),
-				APIKey:      r.Header.Get("API_KEY"),
-				BasicAuth:   r.Header.Get("BASIC_AUTH"),
+		// Certificates are loaded before serving so configuration errors fail fast
+		var tlsCfg *config.TLSConfig
+		var tlsReloader *tlsutil.Reloader
+		if transport.TLS {
+			if tlsCfg, err = config.LoadTLSConfig(); err != nil {
+				log.Fatalf("Invalid TLS configuration: %v", err)
 			}
-
-			if apiCfg.BaseURL == "" {
-				http.Error(w, "Missing API_BASE_URL header", http.StatusBadRequest)
-				return
+			if tlsReloader, err = tlsutil.NewReloader(tlsCfg); err != nil {
+				log.Fatalf("Failed to load TLS certificate: %v", err)
 			}
+			hupChan := make(chan os.Signal, 1)
+			signal.Notify(hupChan, syscall.SIGHUP)
+			go tlsReloader.Watch(hupChan, nil)
+		}
 
-			log.Printf("Incoming HTTP request - BaseURL: %s", apiCfg.BaseURL)
+		// Inbound authentication of MCP clients, enabled by AUTH_* variables
+		// and by TLS_CLIENT_CA_FILE for verified client certificates
+		authCfg := config.LoadAuthConfig()
+		authCfg.ClientCertificates = tlsCfg != nil && tlsCfg.ClientCAFile != ""
+		authenticator, err := auth.New(authCfg)
+		if err != nil {
+			log.Fatalf("Failed to configure authentication: %v", err)
+		}
 
-			// Create MCP server for this request
-			mcpSrv := createMCPServer(apiCfg, transport)
-			handler := server.NewStreamableHTTPServer(mcpSrv, server.WithHTTPContextFunc(
-				func(ctx context.Context, req *http.Request) context.Context {
-					return context.WithValue(ctx, "apiConfig", apiCfg)
-				},
-			))
+		mux := http.NewServeMux()
+		httpServer := &http.Server{Handler: mux}
 
-			handler.ServeHTTP(w, r)
-		})
+		if authenticator != nil {
+			log.Println("Inbound authentication enabled for MCP endpoints")
+			for _, path := range auth.MetadataPaths(authCfg) {
+				mux.Handle(path, auth.MetadataHandler(authCfg))
+			}
+		} else {
+			log.Println("Warning: inbound authentication is disabled; anyone who can reach the port can call the MCP endpoints")
+		}
+
+		if transport.Streamable {
+			streamable := server.NewStreamableHTTPServer(mcpSrv, server.WithHTTPContextFunc(contextFunc))
+			mux.Handle("/mcp", protect(cfg, authCfg, authenticator, streamable))
+		}
 
-		mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		var sseSrv *server.SSEServer
+		if transport.SSE {
+			sseSrv = server.NewSSEServer(mcpSrv,
+				server.WithSSEContextFunc(contextFunc),
+				server.WithHTTPServer(httpServer),
+			)
+			mux.Handle("/sse", protect(cfg, authCfg, authenticator, sseSrv.SSEHandler()))
+			mux.Handle("/message", protect(cfg, authCfg, authenticator, sseSrv.MessageHandler()))
+		}
+
+		// Health check; any other unregistered path is a 404
+		mux.HandleFunc("/{$}", func(w http.ResponseWriter, _ *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
 			w.Write([]byte(`{"status":"ok"}`))
 		})
 
-		addr := net.JoinHostPort("0.0.0.0", port)
-		httpServer := &http.Server{Addr: addr, Handler: mux}
-
 		go func() {
 			// Check if HTTPS mode
-			if isHTTPS {
-				certFile := os.Getenv("CERT_FILE")
-				keyFile := os.Getenv("KEY_FILE")
-				
-				if certFile == "" || keyFile == "" {
-					log.Fatalf("CERT_FILE and KEY_FILE environment variables are required for HTTPS mode")
-				}
-				
+			if tlsReloader != nil {
+				httpServer.TLSConfig = tlsReloader.ServerConfig()
 				log.Printf("Starting HTTPS server on %s", addr)
-				if err := httpServer.ListenAndServeTLS(certFile, keyFile); err != http.ErrServerClosed {
+				if err := httpServer.ServeTLS(listener, "", ""); err != http.ErrServerClosed {
 					log.Fatalf("HTTPS server error: %v", err)
 				}
 			} else {
 				log.Printf("Starting HTTP server on %s", addr)
-				if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+				if err := httpServer.Serve(listener); err != http.ErrServerClosed {
 					log.Fatalf("HTTP server error: %v", err)
 				}
 			}
@@ -108,7 +173,12 @@ func main() {
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		if err := httpServer.Shutdown(ctx); err != nil {
+		// The SSE server closes its open event streams before shutting down httpServer
+		shutdown := httpServer.Shutdown
+		if sseSrv != nil {
+			shutdown = sseSrv.Shutdown
+		}
+		if err := shutdown(ctx); err != nil {
 			log.Printf("Shutdown error: %v", err)
 		} else {
 			log.Println("HTTP server shutdown complete")
@@ -129,17 +199,117 @@ func main() {
 }
 
 func createMCPServer(cfg *config.APIConfig, mode string) *server.MCPServer {
-	mcp := server.NewMCPServer("Docker HUB API", "beta",
+	tools := GetAll(cfg)
+	log.Printf("Loaded %d tools for %s mode", len(tools), mode)
+
+	// Logins used as a session's credential end with the session
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		upstream.Sessions.Forget(session.SessionID())
+		results.Stored.Forget(session.SessionID())
+	})
+
+	opts := []server.ServerOption{
 		server.WithToolCapabilities(true),
 		server.WithRecovery(),
-	)
+		server.WithHooks(hooks),
+		// Lets post_v2_users_login ask for two-factor codes
+		server.WithElicitation(),
+	}
+	// Over HTTP, profiles are only offered to clients an RBAC policy governs
+	selectable := config.LoadAuthConfig().ProfilesSelectable(mode != "STDIO")
+	opts = append(opts, toolServerOptions(cfg, tools, selectable)...)
+	mcp := server.NewMCPServer("Docker HUB API", "beta", opts...)
 
-	tools := GetAll(cfg)
-	log.Printf("Loaded %d tools for %s mode", len(tools), mode)
+	profiles := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		if selectable {
+			profiles = append(profiles, name)
+		}
+	}
+	sort.Strings(profiles)
 
 	for _, tool := range tools {
+		validate.Describe(&tool.Definition)
+		shape.Describe(&tool.Definition)
+		cursor.Describe(&tool.Definition)
+		cache.Describe(&tool.Definition)
+		if len(profiles) > 0 {
+			tool.Definition.InputSchema.Properties[policy.ProfileArgument] = map[string]any{
+				"type":        "string",
+				"description": "Configuration profile to call the API with; defaults to the session's profile",
+				"enum":        profiles,
+			}
+		}
 		mcp.AddTool(tool.Definition, tool.Handler)
 	}
+	// Results too large to return inline, read back in chunks
+	for _, template := range results.Templates() {
+		mcp.AddResourceTemplate(template, results.Stored.ReadHandler)
+	}
 
 	return mcp
+}
+
+// protect applies the origin checks, header checks and inbound
+// authentication shared by every MCP transport endpoint. authenticator is nil
+// when inbound authentication is disabled.
+func protect(cfg *config.APIConfig, authCfg *config.AuthConfig, authenticator auth.Authenticator, next http.Handler) http.Handler {
+	var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if profile := r.Header.Get("PROFILE"); profile != "" {
+			if !authCfg.ProfilesSelectable(true) {
+				http.Error(w, policy.ErrProfileSelection.Error(), http.StatusForbidden)
+				return
+			}
+			if _, err := cfg.ProfileConfig(profile); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			log.Printf("Incoming HTTP request - Profile: %s", profile)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Header.Get("API_BASE_URL") == "" {
+			http.Error(w, "Missing API_BASE_URL or PROFILE header", http.StatusBadRequest)
+			return
+		}
+		if err := upstream.CheckBaseURL(cfg, r.Header.Get("API_BASE_URL")); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		log.Printf("Incoming HTTP request - BaseURL: %s", r.Header.Get("API_BASE_URL"))
+
+		next.ServeHTTP(w, r)
+	})
+	if authenticator != nil {
+		h = auth.Middleware(authenticator, authCfg, h)
+	}
+	return originGuard(cfg, h)
+}
+
+// apiConfigFromHeaders reads the dynamic API configuration supplied by an HTTP client.
+// A PROFILE header selects a CONFIG_FILE profile for the session instead.
+// Restrictions pinned by the server's own configuration are carried over from base.
+func apiConfigFromHeaders(r *http.Request, base *config.APIConfig) *config.APIConfig {
+	if profile, err := base.ProfileConfig(r.Header.Get("PROFILE")); err == nil {
+		return profile
+	}
+	return &config.APIConfig{
+		BaseURL:     r.Header.Get("API_BASE_URL"),
+		BearerToken: r.Header.Get("BEARER_TOKEN"),
+		APIKey:      r.Header.Get("API_KEY"),
+		BasicAuth:   r.Header.Get("BASIC_AUTH"),
+
+		AllowedNamespaces:    base.AllowedNamespaces,
+		PinnedNamespaces:     base.PinnedNamespaces,
+		AllowedBaseURLs:      base.AllowedBaseURLs,
+		AllowPrivateNetworks: base.AllowPrivateNetworks,
+		Upstream:             base.Upstream,
+		SessionLogin:         base.SessionLogin,
+		Timezone:             base.Timezone,
+		Tools:                base.Tools,
+		ExcludeTools:         base.ExcludeTools,
+		Profiles:             base.Profiles,
+	}
 }
\ No newline at end of file
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// post sends an MCP message to handler as a client naming baseURL and token
+// in its headers.
+func post(handler http.Handler, body, session, baseURL, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("API_BASE_URL", baseURL)
+	req.Header.Set("BEARER_TOKEN", token)
+	if session != "" {
+		req.Header.Set("Mcp-Session-Id", session)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// initialize opens an MCP session on handler and returns its ID.
+func initialize(b *testing.B, handler http.Handler, baseURL string) string {
+	b.Helper()
+	initialized := post(handler, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"bench","version":"1"}}}`, "", baseURL, "token")
+	session := initialized.Header().Get("Mcp-Session-Id")
+	if initialized.Code != http.StatusOK || session == "" {
+		b.Fatalf("initialize: %d %s", initialized.Code, initialized.Body)
+	}
+	post(handler, `{"jsonrpc":"2.0","method":"notifications/initialized"}`, session, baseURL, "token")
+	return session
+}
+
+// streamableHandler returns the streamable HTTP handler main serves at /mcp.
+func streamableHandler(cfg *config.APIConfig) http.Handler {
+	mcpSrv := createMCPServer(cfg, "streamable-http")
+	streamable := server.NewStreamableHTTPServer(mcpSrv, server.WithHTTPContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+		return config.WithAPIConfig(ctx, apiConfigFromHeaders(r, cfg))
+	}))
+	return protect(cfg, &config.AuthConfig{}, nil, streamable)
+}
+
+// BenchmarkToolsList measures a tools/list request on the streamable HTTP
+// handler, which is built once and resolves each request's APIConfig from
+// its headers.
+func BenchmarkToolsList(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.APIConfig{AllowedBaseURLs: []string{config.DefaultAllowedBaseURL}}
+	handler := streamableHandler(cfg)
+	session := initialize(b, handler, config.DefaultAllowedBaseURL)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			w := post(handler, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, session, config.DefaultAllowedBaseURL, "token")
+			if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"tools"`) {
+				b.Errorf("tools/list: %d %s", w.Code, w.Body)
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkToolCall measures tools/call requests made in parallel, each
+// naming one of several upstreams and a bearer token of its own, so every
+// call resolves its APIConfig from its headers and reaches its upstream.
+func BenchmarkToolCall(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	var upstreams []string
+	for i := 0; i < 4; i++ {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer token-") {
+				http.Error(w, `{"detail": "no token"}`, http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"active_from": "2026-01-01T00:00:00Z", "statistics": {"total": 3623807, "active": 2911, "inactive": 3620896}}`))
+		}))
+		defer srv.Close()
+		upstreams = append(upstreams, srv.URL)
+	}
+	cfg := &config.APIConfig{AllowedBaseURLs: upstreams, AllowPrivateNetworks: true}
+	handler := streamableHandler(cfg)
+	session := initialize(b, handler, upstreams[0])
+
+	const call = `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"get_v2_namespaces_namespace_repositories_repository_images-summary","arguments":{"namespace":"library","repository":"alpine"}}}`
+	var calls atomic.Int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			n := calls.Add(1)
+			w := post(handler, call, session, upstreams[n%int64(len(upstreams))], fmt.Sprintf("token-%d", n))
+			if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "3623807") {
+				b.Errorf("tools/call: %d %s", w.Code, w.Body)
+				return
+			}
+		}
+	})
+}
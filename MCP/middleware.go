@@ -0,0 +1,83 @@
+package main
+
+import (
+	"log"
+
+	"github.com/docker-hub-api/mcp-server/cache"
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/cursor"
+	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/policy"
+	"github.com/docker-hub-api/mcp-server/shape"
+	"github.com/docker-hub-api/mcp-server/times"
+	"github.com/docker-hub-api/mcp-server/validate"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolServerOptions returns the tool call middlewares and tools/list filters
+// enabled by the environment. Middlewares run in the order they are added,
+// before any handler in tools/. Calls naming a profile are refused unless
+// selectable.
+func toolServerOptions(cfg *config.APIConfig, tools []models.Tool, selectable bool) []server.ServerOption {
+	var opts []server.ServerOption
+
+	// Cursors are opened first so every check sees the arguments they stand for
+	opts = append(opts, server.WithToolHandlerMiddleware(cursor.Expand))
+
+	// Profile selection runs next so every later check sees the chosen profile
+	if len(cfg.Profiles) > 0 {
+		log.Printf("Loaded %d profiles from CONFIG_FILE", len(cfg.Profiles))
+		opts = append(opts, server.WithToolHandlerMiddleware(policy.SelectProfile(cfg, selectable)))
+	}
+
+	// Arguments are checked against the spec and coerced before any guard reads
+	// them, so the guards and the handler see the same values. Times are read in
+	// the zone of the chosen profile.
+	opts = append(opts, server.WithToolHandlerMiddleware(validate.Middleware(cfg)))
+
+	// Handlers that go on to act as another tool check it with policy.Permit
+	var checkers []policy.Checker
+
+	authCfg := config.LoadAuthConfig()
+	if authCfg.RBACPolicyFile != "" {
+		rbac, err := policy.LoadRBAC(authCfg.RBACPolicyFile, tools)
+		if err != nil {
+			log.Fatalf("Failed to load RBAC policy: %v", err)
+		}
+		log.Printf("RBAC policy loaded from %s", authCfg.RBACPolicyFile)
+		opts = append(opts,
+			server.WithToolHandlerMiddleware(rbac.Middleware),
+			server.WithToolFilter(rbac.Filter),
+		)
+		checkers = append(checkers, rbac)
+	}
+
+	profileTools, err := policy.NewProfileTools(cfg, tools)
+	if err != nil {
+		log.Fatalf("Invalid tool filter: %v", err)
+	}
+	opts = append(opts,
+		server.WithToolHandlerMiddleware(profileTools.Middleware),
+		server.WithToolFilter(profileTools.Filter),
+	)
+
+	if len(cfg.PinnedNamespaces) > 0 {
+		log.Printf("Tool calls restricted to namespaces: %v", cfg.PinnedNamespaces)
+	}
+	namespaceGuard := policy.NewNamespaceGuard(cfg, tools)
+	opts = append(opts, server.WithToolHandlerMiddleware(namespaceGuard.Middleware))
+	checkers = append(checkers, profileTools, namespaceGuard)
+	opts = append(opts, server.WithToolHandlerMiddleware(policy.WithCheckers(checkers...)))
+
+	// Results are shaped after their timestamps were annotated. The cache runs
+	// innermost, so cached responses are annotated again on every call and
+	// their relative times stay current.
+	opts = append(opts,
+		server.WithToolHandlerMiddleware(shape.Middleware(tools)),
+		server.WithToolHandlerMiddleware(times.ResultMiddleware(cfg)),
+		server.WithToolHandlerMiddleware(cursor.Rewrite),
+		server.WithToolHandlerMiddleware(cache.Middleware),
+	)
+
+	return opts
+}
@@ -8,6 +8,7 @@ import (
 type Tool struct {
 	Definition mcp.Tool
 	Handler    func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	Group      string // API area the tool belongs to, e.g. "access_tokens"
 }
 
 // ValueError represents the ValueError schema from the OpenAPI specification
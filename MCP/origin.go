@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+// corsAllowedHeaders are the request headers browser clients may send to the MCP endpoints.
+var corsAllowedHeaders = strings.Join([]string{
+	"Content-Type", "Accept", "Authorization", "X-API-Key",
+	"Mcp-Session-Id", "Mcp-Protocol-Version", "Last-Event-ID",
+	"API_BASE_URL", "BEARER_TOKEN", "API_KEY", "BASIC_AUTH",
+}, ", ")
+
+// originGuard validates the Origin header of MCP requests to prevent DNS
+// rebinding and answers CORS preflights for browser based clients. Requests
+// without an Origin header (non-browser clients) pass through unchanged.
+func originGuard(cfg *config.APIConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		allowed, listed := originAllowed(cfg.AllowedOrigins, origin)
+		if !allowed {
+			http.Error(w, "Origin not allowed", http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		// Any page may call the server under "*", so it must not do so with the
+		// browser's cookies or client certificate
+		if listed {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id, WWW-Authenticate")
+
+		// Preflights carry no credentials, so they are answered before authentication
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed matches origin against ALLOWED_ORIGINS. Entries are exact
+// origins such as "https://app.example.com" or "*". With no entries only
+// loopback origins are accepted. listed reports whether origin was accepted by
+// an exact entry or as loopback rather than only by "*".
+func originAllowed(allowed []string, origin string) (ok, listed bool) {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false, false
+	}
+	if len(allowed) == 0 {
+		host := u.Hostname()
+		if host == "localhost" {
+			return true, true
+		}
+		ip := net.ParseIP(host)
+		loopback := ip != nil && ip.IsLoopback()
+		return loopback, loopback
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(strings.TrimSuffix(a, "/"), origin) {
+			return true, true
+		}
+		if a == "*" {
+			ok = true
+		}
+	}
+	return ok, false
+}
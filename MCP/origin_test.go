@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+func TestOriginGuard(t *testing.T) {
+	tests := []struct {
+		name            string
+		allowed         []string
+		origin          string
+		preflight       bool
+		wantStatus      int
+		wantCredentials bool
+	}{
+		{name: "no origin", wantStatus: http.StatusOK},
+		{name: "loopback by default", origin: "http://localhost:3000", wantStatus: http.StatusOK, wantCredentials: true},
+		{name: "loopback IP by default", origin: "http://127.0.0.1:8080", wantStatus: http.StatusOK, wantCredentials: true},
+		{name: "remote refused by default", origin: "https://evil.example", wantStatus: http.StatusForbidden},
+		{name: "listed origin", allowed: []string{"https://app.example.com/"}, origin: "https://app.example.com", wantStatus: http.StatusOK, wantCredentials: true},
+		{name: "unlisted origin", allowed: []string{"https://app.example.com"}, origin: "https://evil.example", wantStatus: http.StatusForbidden},
+		{name: "loopback not listed", allowed: []string{"https://app.example.com"}, origin: "http://localhost:3000", wantStatus: http.StatusForbidden},
+		{name: "wildcard omits credentials", allowed: []string{"*"}, origin: "https://evil.example", wantStatus: http.StatusOK},
+		{name: "wildcard and listed origin", allowed: []string{"*", "https://app.example.com"}, origin: "https://app.example.com", wantStatus: http.StatusOK, wantCredentials: true},
+		{name: "wildcard preflight", allowed: []string{"*"}, origin: "https://evil.example", preflight: true, wantStatus: http.StatusNoContent},
+		{name: "listed preflight", allowed: []string{"https://app.example.com"}, origin: "https://app.example.com", preflight: true, wantStatus: http.StatusNoContent, wantCredentials: true},
+		{name: "malformed origin", allowed: []string{"*"}, origin: "null", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := originGuard(&config.APIConfig{AllowedOrigins: tt.allowed}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.preflight {
+				req.Method = http.MethodOptions
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if credentials := w.Header().Get("Access-Control-Allow-Credentials") == "true"; credentials != tt.wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials sent = %v, want %v", credentials, tt.wantCredentials)
+			}
+			wantOrigin := ""
+			if tt.wantStatus != http.StatusForbidden {
+				wantOrigin = tt.origin
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, wantOrigin)
+			}
+		})
+	}
+}
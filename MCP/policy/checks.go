@@ -0,0 +1,39 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Checker decides whether the caller in ctx may call tool with args. RBAC,
+// ProfileTools and NamespaceGuard are Checkers.
+type Checker interface {
+	Check(ctx context.Context, tool string, args map[string]any) error
+}
+
+type checkersKey struct{}
+
+// WithCheckers returns a middleware that records checkers in the context of
+// tool calls, so a handler that goes on to act as another tool is held to the
+// same checks through Permit.
+func WithCheckers(checkers ...Checker) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return next(context.WithValue(ctx, checkersKey{}, checkers), request)
+		}
+	}
+}
+
+// Permit returns the first error of the checkers recorded in ctx for a call of
+// tool with args, or nil when they all allow it.
+func Permit(ctx context.Context, tool string, args map[string]any) error {
+	checkers, _ := ctx.Value(checkersKey{}).([]Checker)
+	for _, c := range checkers {
+		if err := c.Check(ctx, tool, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
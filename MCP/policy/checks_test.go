@@ -0,0 +1,52 @@
+package policy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPermit(t *testing.T) {
+	const tags = "get_v2_namespaces_namespace_repositories_repository_tags"
+	base := &config.APIConfig{
+		Profiles: map[string]*config.APIConfig{"audit": {Profile: "audit", Tools: []string{"audit_logs"}}},
+	}
+	profileTools, err := NewProfileTools(base, rbacTools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	guard := NewNamespaceGuard(&config.APIConfig{PinnedNamespaces: []string{"acme"}}, rbacTools)
+
+	tests := []struct {
+		name     string
+		checkers []Checker
+		profile  string
+		tool     string
+		args     map[string]any
+		wantErr  string
+	}{
+		{name: "no checkers", tool: tags, args: map[string]any{"namespace": "other"}},
+		{name: "all allow", checkers: []Checker{profileTools, guard}, tool: tags, args: map[string]any{"namespace": "acme"}},
+		{name: "profile tool filter", checkers: []Checker{profileTools, guard}, profile: "audit", tool: tags, args: map[string]any{"namespace": "acme"}, wantErr: `not enabled for profile "audit"`},
+		{name: "namespace guard", checkers: []Checker{profileTools, guard}, tool: tags, args: map[string]any{"namespace": "other"}, wantErr: `namespace "other"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			handler := WithCheckers(tt.checkers...)(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				if tt.profile != "" {
+					ctx = config.WithAPIConfig(ctx, base.Profiles[tt.profile])
+				}
+				err = Permit(ctx, tt.tool, tt.args)
+				return mcp.NewToolResultText("ok"), nil
+			})
+			handler(context.Background(), mcp.CallToolRequest{})
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Permit = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NamespaceGuard rejects tool calls that address namespaces outside the
+// effective APIConfig's PinnedNamespaces or AllowedNamespaces.
+type NamespaceGuard struct {
+	cfg    *config.APIConfig
+	groups map[string]string // tool name -> tool group
+}
+
+// NewNamespaceGuard returns a guard that falls back to cfg when a call carries
+// no per-request configuration.
+func NewNamespaceGuard(cfg *config.APIConfig, tools []models.Tool) *NamespaceGuard {
+	g := &NamespaceGuard{cfg: cfg, groups: make(map[string]string, len(tools))}
+	for _, t := range tools {
+		g.groups[t.Definition.Name] = t.Group
+	}
+	return g
+}
+
+// Check returns an error naming the first target of args that is not allowed.
+// A target must match both the server-wide pin and the profile's own list.
+func (g *NamespaceGuard) Check(ctx context.Context, tool string, args map[string]any) error {
+	cfg := config.FromContext(ctx, g.cfg)
+	if len(cfg.PinnedNamespaces) == 0 && len(cfg.AllowedNamespaces) == 0 {
+		return nil
+	}
+	targets, err := Targets(g.groups[tool], args)
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if len(cfg.PinnedNamespaces) > 0 && !MatchTarget(cfg.PinnedNamespaces, t) {
+			return fmt.Errorf("namespace %q is not in ALLOWED_NAMESPACES", t)
+		}
+		if len(cfg.AllowedNamespaces) > 0 && !MatchTarget(cfg.AllowedNamespaces, t) {
+			if cfg.Profile != "" {
+				return fmt.Errorf("namespace %q is not allowed for profile %q", t, cfg.Profile)
+			}
+			return fmt.Errorf("namespace %q is not in the allowed namespaces", t)
+		}
+	}
+	return nil
+}
+
+func (g *NamespaceGuard) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, _ := request.Params.Arguments.(map[string]any)
+		if err := g.Check(ctx, request.Params.Name, args); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Access denied: %v", err)), nil
+		}
+		return next(ctx, request)
+	}
+}
@@ -0,0 +1,42 @@
+package policy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestNamespaceGuardCheck(t *testing.T) {
+	tools := []models.Tool{{Definition: mcp.Tool{Name: "list_tags"}, Group: "repositories"}}
+	tests := []struct {
+		name      string
+		pinned    []string
+		allowed   []string
+		profile   string
+		namespace string
+		want      string // Part of the error; empty when the call is allowed
+	}{
+		{name: "no lists", namespace: "other"},
+		{name: "pinned", pinned: []string{"acme*"}, namespace: "acme-web"},
+		{name: "outside the pin", pinned: []string{"acme"}, namespace: "other", want: "not in ALLOWED_NAMESPACES"},
+		{name: "profile list only", allowed: []string{"acme"}, namespace: "other", want: "not in the allowed namespaces"},
+		{name: "profile cannot widen the pin", pinned: []string{"acme"}, allowed: []string{"*"}, profile: "ops", namespace: "other", want: "not in ALLOWED_NAMESPACES"},
+		{name: "profile narrows the pin", pinned: []string{"acme*"}, allowed: []string{"acme"}, profile: "ops", namespace: "acme-web", want: `not allowed for profile "ops"`},
+		{name: "in both", pinned: []string{"acme*"}, allowed: []string{"acme"}, profile: "ops", namespace: "acme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.APIConfig{PinnedNamespaces: tt.pinned, AllowedNamespaces: tt.allowed, Profile: tt.profile}
+			g := NewNamespaceGuard(&config.APIConfig{}, tools)
+			ctx := config.WithAPIConfig(context.Background(), cfg)
+			err := g.Check(ctx, "list_tags", map[string]any{"namespace": tt.namespace, "repository": "web"})
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
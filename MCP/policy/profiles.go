@@ -0,0 +1,124 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ProfileArgument is the optional tool argument that selects a CONFIG_FILE profile.
+const ProfileArgument = "profile"
+
+// ErrProfileSelection is returned when clients may not select profiles; see
+// config.AuthConfig.ProfilesSelectable.
+var ErrProfileSelection = errors.New("selecting a profile needs an RBAC policy (RBAC_POLICY_FILE) or ALLOW_PROFILE_SELECTION=true on the server")
+
+// SelectProfile returns a middleware that switches the effective APIConfig to
+// the profile named by a call's "profile" argument. Calls without the argument
+// keep the configuration of their HTTP session, or base in STDIO mode. Unless
+// selectable, calls naming a profile are refused.
+func SelectProfile(base *config.APIConfig, selectable bool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args, _ := request.Params.Arguments.(map[string]any)
+			name, _ := args[ProfileArgument].(string)
+			if name == "" {
+				return next(ctx, request)
+			}
+			if !selectable {
+				return mcp.NewToolResultError(fmt.Sprintf("Access denied: %v", ErrProfileSelection)), nil
+			}
+			profile, err := base.ProfileConfig(name)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return next(config.WithAPIConfig(ctx, profile), request)
+		}
+	}
+}
+
+// ProfileTools enforces the tool filters (Tools and ExcludeTools) of the
+// effective APIConfig.
+type ProfileTools struct {
+	base  *config.APIConfig
+	index *ToolIndex
+}
+
+// NewProfileTools validates the tool filters of base and its profiles.
+func NewProfileTools(base *config.APIConfig, tools []models.Tool) (*ProfileTools, error) {
+	p := &ProfileTools{base: base, index: NewToolIndex(tools)}
+	configs := map[string]*config.APIConfig{"default configuration": base}
+	for name, c := range base.Profiles {
+		configs["profile "+name] = c
+	}
+	for who, c := range configs {
+		if _, err := p.allowed(c); err != nil {
+			return nil, fmt.Errorf("%s: %w", who, err)
+		}
+	}
+	return p, nil
+}
+
+// allowed returns the tools cfg may call, or nil when it has no filters.
+func (p *ProfileTools) allowed(cfg *config.APIConfig) (map[string]bool, error) {
+	if len(cfg.Tools) == 0 && len(cfg.ExcludeTools) == 0 {
+		return nil, nil
+	}
+	set := p.index.All()
+	if len(cfg.Tools) > 0 {
+		var err error
+		if set, err = p.index.Resolve(cfg.Tools, nil); err != nil {
+			return nil, err
+		}
+	}
+	exclude, err := p.index.Resolve(cfg.ExcludeTools, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name := range exclude {
+		delete(set, name)
+	}
+	return set, nil
+}
+
+// Check returns an error when the effective configuration does not enable tool.
+func (p *ProfileTools) Check(ctx context.Context, tool string, _ map[string]any) error {
+	cfg := config.FromContext(ctx, p.base)
+	set, err := p.allowed(cfg)
+	if err != nil || set == nil || set[tool] {
+		return err
+	}
+	if cfg.Profile != "" {
+		return fmt.Errorf("tool %q is not enabled for profile %q", tool, cfg.Profile)
+	}
+	return fmt.Errorf("tool %q is not enabled", tool)
+}
+
+func (p *ProfileTools) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err := p.Check(ctx, request.Params.Name, nil); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Access denied: %v", err)), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// Filter hides tools the effective configuration does not enable from tools/list.
+func (p *ProfileTools) Filter(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	set, err := p.allowed(config.FromContext(ctx, p.base))
+	if err != nil || set == nil {
+		return tools
+	}
+	allowed := make([]mcp.Tool, 0, len(tools))
+	for _, t := range tools {
+		if set[t.Name] {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed
+}
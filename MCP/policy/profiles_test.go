@@ -0,0 +1,51 @@
+package policy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSelectProfile(t *testing.T) {
+	base := &config.APIConfig{Profiles: map[string]*config.APIConfig{"ops": {Profile: "ops"}}}
+	tests := []struct {
+		name       string
+		selectable bool
+		profile    string
+		want       string // Profile the handler sees, or part of the tool error
+		denied     bool
+	}{
+		{name: "no profile argument", profile: "", want: ""},
+		{name: "selectable", selectable: true, profile: "ops", want: "ops"},
+		{name: "unknown profile", selectable: true, profile: "prod", want: "unknown profile", denied: true},
+		{name: "not selectable", profile: "ops", want: "Access denied", denied: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := SelectProfile(base, tt.selectable)(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				seen = config.FromContext(ctx, base).Profile
+				return mcp.NewToolResultText("ok"), nil
+			})
+			var req mcp.CallToolRequest
+			req.Params.Arguments = map[string]any{ProfileArgument: tt.profile}
+			res, err := handler(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.IsError != tt.denied {
+				t.Fatalf("IsError = %v, want %v", res.IsError, tt.denied)
+			}
+			if tt.denied {
+				if text := res.Content[0].(mcp.TextContent).Text; !strings.Contains(text, tt.want) {
+					t.Errorf("got %q, want %q", text, tt.want)
+				}
+			} else if seen != tt.want {
+				t.Errorf("handler saw profile %q, want %q", seen, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/docker-hub-api/mcp-server/auth"
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule lists what one client may do. Tools holds tool names or group names;
+// Namespaces holds patterns accepted by MatchTarget.
+type Rule struct {
+	Tools      []string `yaml:"tools"`
+	Namespaces []string `yaml:"namespaces"`
+	Profiles   []string `yaml:"profiles"` // CONFIG_FILE profiles the client may select; "*" allows all
+}
+
+// RBACFile is the on-disk policy format.
+//
+//	groups:
+//	  tag-readers: [get_v2_namespaces_namespace_repositories_repository_tags]
+//	clients:
+//	  api_key:ci-bot:
+//	    tools: [tag-readers]
+//	    namespaces: ["acme/*"]
+//	    profiles: [org-bot]
+//	default:
+//	  tools: []
+type RBACFile struct {
+	Groups  map[string][]string `yaml:"groups"`
+	Clients map[string]Rule     `yaml:"clients"`
+	Default *Rule               `yaml:"default"`
+}
+
+type grant struct {
+	tools      map[string]bool
+	namespaces []string
+	profiles   []string
+}
+
+// clientMethods are the authentication methods a client name may start with.
+var clientMethods = []string{"api_key", "bearer", "client_cert"}
+
+// RBAC maps authenticated clients to the tools and namespaces they may use.
+type RBAC struct {
+	clients map[string]*grant
+	def     *grant
+	index   *ToolIndex
+}
+
+// LoadRBAC reads a policy file and resolves its group references against tools.
+// Besides groups declared in the file, every tool group ("images",
+// "access_tokens", ...), "read" (get/head tools), "write" and "*" are built in.
+func LoadRBAC(path string, tools []models.Tool) (*RBAC, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RBAC policy: %w", err)
+	}
+	var file RBACFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse RBAC policy: %w", err)
+	}
+
+	index := NewToolIndex(tools)
+	r := &RBAC{clients: make(map[string]*grant), index: index}
+
+	resolve := func(who string, rule Rule) (*grant, error) {
+		set, err := index.Resolve(rule.Tools, file.Groups)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", who, err)
+		}
+		return &grant{tools: set, namespaces: rule.Namespaces, profiles: rule.Profiles}, nil
+	}
+
+	for client, rule := range file.Clients {
+		method, subject, _ := strings.Cut(client, ":")
+		if !slices.Contains(clientMethods, method) || subject == "" {
+			return nil, fmt.Errorf("client %q: name clients by method and identity, e.g. api_key:ci-bot, bearer:<sub> or client_cert:<subject DN>", client)
+		}
+		g, err := resolve("client "+client, rule)
+		if err != nil {
+			return nil, err
+		}
+		r.clients[client] = g
+	}
+	if file.Default != nil {
+		if r.def, err = resolve("default", *file.Default); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReadOnly reports whether the named tool only reads from the API.
+func IsReadOnly(tool string) bool {
+	return strings.HasPrefix(tool, "get_") || strings.HasPrefix(tool, "head_") || tool == "whoami"
+}
+
+func (r *RBAC) grantFor(ctx context.Context) (string, *grant) {
+	if p, ok := auth.PrincipalFromContext(ctx); ok {
+		if g, ok := r.clients[p.ID()]; ok {
+			return p.ID(), g
+		}
+		return p.ID(), r.def
+	}
+	return "anonymous", r.def
+}
+
+// Check returns a descriptive error when the caller in ctx may not call tool with args.
+func (r *RBAC) Check(ctx context.Context, tool string, args map[string]any) error {
+	client, g := r.grantFor(ctx)
+	if g == nil {
+		return fmt.Errorf("client %q has no RBAC policy", client)
+	}
+	if !g.tools[tool] {
+		return fmt.Errorf("client %q may not call tool %q", client, tool)
+	}
+	targets, err := Targets(r.index.Group(tool), args)
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if !MatchTarget(g.namespaces, t) {
+			return fmt.Errorf("client %q may not access namespace %q", client, t)
+		}
+	}
+	if cfg := config.FromContext(ctx, nil); cfg != nil && cfg.Profile != "" && !matchAny(g.profiles, cfg.Profile) {
+		return fmt.Errorf("client %q may not use profile %q", client, cfg.Profile)
+	}
+	return nil
+}
+
+// Middleware rejects tool calls the policy does not allow before the handler runs.
+func (r *RBAC) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, _ := request.Params.Arguments.(map[string]any)
+		if err := r.Check(ctx, request.Params.Name, args); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Access denied: %v", err)), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// Filter hides tools the caller may not use from tools/list.
+func (r *RBAC) Filter(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	_, g := r.grantFor(ctx)
+	allowed := make([]mcp.Tool, 0, len(tools))
+	for _, t := range tools {
+		if g != nil && g.tools[t.Name] {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,150 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker-hub-api/mcp-server/auth"
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var rbacTools = []models.Tool{
+	{Definition: mcp.Tool{Name: "get_v2_namespaces_namespace_repositories_repository_tags"}, Group: "repositories"},
+	{Definition: mcp.Tool{Name: "post_v2_namespaces_namespace_delete-images"}, Group: "images"},
+	{Definition: mcp.Tool{Name: "get_v2_auditlogs_account"}, Group: "audit_logs"},
+	{Definition: mcp.Tool{Name: "reveal_secret"}, Group: SecretsGroup},
+}
+
+func loadTestRBAC(t *testing.T, policy string) (*RBAC, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rbac.yaml")
+	if err := os.WriteFile(path, []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return LoadRBAC(path, rbacTools)
+}
+
+func TestRBACCheck(t *testing.T) {
+	r, err := loadTestRBAC(t, `
+groups:
+  tag-readers: [get_v2_namespaces_namespace_repositories_repository_tags]
+clients:
+  api_key:ci-bot:
+    tools: [tag-readers]
+    namespaces: ["acme/web-*"]
+  bearer:ops:
+    tools: ["*"]
+    namespaces: ["*"]
+    profiles: [staging]
+  client_cert:CN=auditor,O=Example:
+    tools: [audit_logs, reveal_secret]
+    namespaces: [acme]
+default:
+  tools: [read]
+  namespaces: [public]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const tags = "get_v2_namespaces_namespace_repositories_repository_tags"
+	tests := []struct {
+		name    string
+		who     *auth.Principal
+		profile string
+		tool    string
+		args    map[string]any
+		want    string // Part of the denial; empty when allowed
+	}{
+		{name: "granted group and repository", who: &auth.Principal{Method: "api_key", Subject: "ci-bot"}, tool: tags, args: map[string]any{"namespace": "acme", "repository": "web-app"}},
+		{name: "repository outside the glob", who: &auth.Principal{Method: "api_key", Subject: "ci-bot"}, tool: tags, args: map[string]any{"namespace": "acme", "repository": "api"}, want: `may not access namespace "acme/api"`},
+		{name: "tool outside the grant", who: &auth.Principal{Method: "api_key", Subject: "ci-bot"}, tool: "post_v2_namespaces_namespace_delete-images", args: map[string]any{"namespace": "acme"}, want: "may not call tool"},
+		{name: "same subject by another method", who: &auth.Principal{Method: "bearer", Subject: "ci-bot"}, tool: tags, args: map[string]any{"namespace": "acme", "repository": "web-app"}, want: `client "bearer:ci-bot" may not access namespace`},
+		{name: "star excludes secrets", who: &auth.Principal{Method: "bearer", Subject: "ops"}, tool: "reveal_secret", want: "may not call tool"},
+		{name: "secrets granted by name", who: &auth.Principal{Method: "client_cert", Subject: "CN=auditor,O=Example"}, tool: "reveal_secret"},
+		{name: "granted profile", who: &auth.Principal{Method: "bearer", Subject: "ops"}, profile: "staging", tool: tags, args: map[string]any{"namespace": "x", "repository": "y"}},
+		{name: "other profile", who: &auth.Principal{Method: "bearer", Subject: "ops"}, profile: "prod", tool: tags, args: map[string]any{"namespace": "x", "repository": "y"}, want: `may not use profile "prod"`},
+		{name: "profile without profiles", who: &auth.Principal{Method: "client_cert", Subject: "CN=auditor,O=Example"}, profile: "staging", tool: "get_v2_auditlogs_account", args: map[string]any{"account": "acme"}, want: "may not use profile"},
+		{name: "client certificate subject", who: &auth.Principal{Method: "client_cert", Subject: "CN=auditor,O=Example"}, tool: "get_v2_auditlogs_account", args: map[string]any{"account": "acme"}},
+		{name: "unlisted client falls back to default", who: &auth.Principal{Method: "bearer", Subject: "eve"}, tool: tags, args: map[string]any{"namespace": "public", "repository": "x"}},
+		{name: "default is read only", who: &auth.Principal{Method: "bearer", Subject: "eve"}, tool: "post_v2_namespaces_namespace_delete-images", args: map[string]any{"namespace": "public"}, want: "may not call tool"},
+		{name: "anonymous uses default", tool: tags, args: map[string]any{"namespace": "acme", "repository": "x"}, want: `client "anonymous" may not access namespace`},
+		{name: "unreadable target", who: &auth.Principal{Method: "bearer", Subject: "ops"}, tool: "post_v2_namespaces_namespace_delete-images", args: map[string]any{"namespace": "acme", "manifests": "[]"}, want: "manifests"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.who != nil {
+				ctx = auth.WithPrincipal(ctx, tt.who)
+			}
+			ctx = config.WithAPIConfig(ctx, &config.APIConfig{Profile: tt.profile})
+			err := r.Check(ctx, tt.tool, tt.args)
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRBACWithoutDefault(t *testing.T) {
+	r, err := loadTestRBAC(t, "clients:\n  bearer:ops:\n    tools: [read]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Method: "bearer", Subject: "eve"})
+	if err := r.Check(ctx, "get_v2_auditlogs_account", map[string]any{"account": "acme"}); err == nil || !strings.Contains(err.Error(), "has no RBAC policy") {
+		t.Errorf("got %v", err)
+	}
+	if got := r.Filter(ctx, []mcp.Tool{{Name: "get_v2_auditlogs_account"}}); len(got) != 0 {
+		t.Errorf("Filter = %v, want none", got)
+	}
+}
+
+func TestLoadRBACErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   string
+	}{
+		{"client without method", "clients:\n  ci-bot:\n    tools: [read]\n", "name clients by method"},
+		{"unknown method", "clients:\n  oauth:ci-bot:\n    tools: [read]\n", "name clients by method"},
+		{"empty identity", "clients:\n  \"bearer:\":\n    tools: [read]\n", "name clients by method"},
+		{"unknown tool", "clients:\n  bearer:ops:\n    tools: [get_nothing]\n", "unknown tool or group"},
+		{"unknown tool in group", "groups:\n  g: [get_nothing]\nclients:\n  bearer:ops:\n    tools: [g]\n", `group "g": unknown tool`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadTestRBAC(t, tt.policy); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchTarget(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		target   Target
+		want     bool
+	}{
+		{[]string{"acme"}, Target{Namespace: "acme"}, true},
+		{[]string{"acme"}, Target{Namespace: "acme", Repository: "web"}, true},
+		{[]string{"acme"}, Target{Namespace: "acme-labs"}, false},
+		{[]string{"acme-*"}, Target{Namespace: "acme-labs"}, true},
+		{[]string{"acme/web-*"}, Target{Namespace: "acme", Repository: "web-app"}, true},
+		{[]string{"acme/web-*"}, Target{Namespace: "acme", Repository: "api"}, false},
+		{[]string{"acme/web-*"}, Target{Namespace: "acme"}, true},
+		{[]string{"*/public-*"}, Target{Namespace: "partner", Repository: "public-docs"}, true},
+		{[]string{"other", "acme/*"}, Target{Namespace: "acme", Repository: "web"}, true},
+		{nil, Target{Namespace: "acme"}, false},
+	}
+	for _, tt := range tests {
+		if got := MatchTarget(tt.patterns, tt.target); got != tt.want {
+			t.Errorf("MatchTarget(%v, %v) = %v, want %v", tt.patterns, tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Target is a Docker Hub namespace, optionally narrowed to one repository,
+// that a tool call acts on.
+type Target struct {
+	Namespace  string
+	Repository string
+}
+
+func (t Target) String() string {
+	if t.Repository == "" {
+		return t.Namespace
+	}
+	return t.Namespace + "/" + t.Repository
+}
+
+// Targets returns every namespace and repository addressed by the arguments
+// of a call to a tool in group. Organization settings tools address their
+// org through "name"; elsewhere "name" is a filter and is not a target.
+// Arguments that would address a target but have the wrong type are an
+// error, so a value the guards cannot read never reaches the handler.
+func Targets(group string, args map[string]any) ([]Target, error) {
+	var targets []Target
+	namespace, err := stringArg(args, "namespace")
+	if err != nil {
+		return nil, err
+	}
+	repository, err := stringArg(args, "repository")
+	if err != nil {
+		return nil, err
+	}
+	if namespace != "" {
+		targets = append(targets, Target{Namespace: namespace, Repository: repository})
+	}
+	account, err := stringArg(args, "account")
+	if err != nil {
+		return nil, err
+	}
+	if account != "" {
+		targets = append(targets, Target{Namespace: account})
+	}
+	if group == "org_settings" {
+		name, err := stringArg(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		if name != "" {
+			targets = append(targets, Target{Namespace: name})
+		}
+	}
+	if value, ok := args["manifests"]; ok && value != nil {
+		manifests, isArray := value.([]any)
+		if !isArray {
+			return nil, fmt.Errorf("manifests must be an array")
+		}
+		for i, m := range manifests {
+			item, isObject := m.(map[string]any)
+			if !isObject {
+				return nil, fmt.Errorf("manifests[%d] must be an object", i)
+			}
+			repo, err := stringArg(item, "repository")
+			if err != nil {
+				return nil, fmt.Errorf("manifests[%d].%w", i, err)
+			}
+			targets = append(targets, Target{Namespace: namespace, Repository: repo})
+		}
+	}
+	return targets, nil
+}
+
+// stringArg returns the named argument, which must be a string when present.
+func stringArg(args map[string]any, name string) (string, error) {
+	value, ok := args[name]
+	if !ok || value == nil {
+		return "", nil
+	}
+	s, isString := value.(string)
+	if !isString {
+		return "", fmt.Errorf("%s must be a string", name)
+	}
+	return s, nil
+}
+
+// MatchTarget reports whether t is covered by one of patterns. A pattern is a
+// namespace glob ("acme", "acme-*") optionally followed by "/" and a repository
+// glob ("acme/*", "acme/web-*"). A repository glob only applies when the target
+// names a repository.
+func MatchTarget(patterns []string, t Target) bool {
+	for _, p := range patterns {
+		nsPattern, repoPattern, hasRepo := strings.Cut(p, "/")
+		if ok, _ := path.Match(nsPattern, t.Namespace); !ok {
+			continue
+		}
+		if !hasRepo || t.Repository == "" {
+			return true
+		}
+		if ok, _ := path.Match(repoPattern, t.Repository); ok {
+			return true
+		}
+	}
+	return false
+}
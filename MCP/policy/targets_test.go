@@ -0,0 +1,91 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   string
+		args    map[string]any
+		want    []Target
+		wantErr bool
+	}{
+		{
+			name:  "namespace and repository",
+			group: "repositories",
+			args:  map[string]any{"namespace": "acme", "repository": "web"},
+			want:  []Target{{Namespace: "acme", Repository: "web"}},
+		},
+		{
+			name:  "audit log account",
+			group: "audit_logs",
+			args:  map[string]any{"account": "acme"},
+			want:  []Target{{Namespace: "acme"}},
+		},
+		{
+			name:  "org settings name",
+			group: "org_settings",
+			args:  map[string]any{"name": "acme"},
+			want:  []Target{{Namespace: "acme"}},
+		},
+		{
+			name:  "name is a filter elsewhere",
+			group: "audit_logs",
+			args:  map[string]any{"account": "acme", "name": "other"},
+			want:  []Target{{Namespace: "acme"}},
+		},
+		{
+			name:  "manifests",
+			group: "images",
+			args: map[string]any{"namespace": "acme", "manifests": []any{
+				map[string]any{"repository": "web", "digest": "sha256:1"},
+				map[string]any{"repository": "api", "digest": "sha256:2"},
+			}},
+			want: []Target{{Namespace: "acme"}, {Namespace: "acme", Repository: "web"}, {Namespace: "acme", Repository: "api"}},
+		},
+		{
+			name:    "manifests as JSON text",
+			group:   "images",
+			args:    map[string]any{"namespace": "acme", "manifests": `[{"repository":"../other","digest":"sha256:1"}]`},
+			wantErr: true,
+		},
+		{
+			name:    "manifest that is not an object",
+			group:   "images",
+			args:    map[string]any{"namespace": "acme", "manifests": []any{"web"}},
+			wantErr: true,
+		},
+		{
+			name:    "manifest repository that is not a string",
+			group:   "images",
+			args:    map[string]any{"namespace": "acme", "manifests": []any{map[string]any{"repository": 1.0}}},
+			wantErr: true,
+		},
+		{
+			name:    "namespace that is not a string",
+			group:   "repositories",
+			args:    map[string]any{"namespace": []any{"acme"}},
+			wantErr: true,
+		},
+		{
+			name:    "org name that is not a string",
+			group:   "org_settings",
+			args:    map[string]any{"name": map[string]any{}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Targets(tt.group, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/docker-hub-api/mcp-server/models"
+)
+
+// ToolIndex resolves tool names and group names to sets of tools. Every tool
+// group ("images", "access_tokens", ...), "read" (get/head tools), "write" and
+// "*" are built in. Tools in the "secrets" group are never part of "read",
+// "write" or "*"; they must be granted by name or group.
+type ToolIndex struct {
+	groups  map[string]string   // tool name -> tool group
+	builtin map[string][]string // group name -> tool names
+}
+
+// SecretsGroup is the group of tools that reveal vault secrets.
+const SecretsGroup = "secrets"
+
+// NewToolIndex indexes tools by name and group.
+func NewToolIndex(tools []models.Tool) *ToolIndex {
+	x := &ToolIndex{groups: make(map[string]string), builtin: make(map[string][]string)}
+	for _, t := range tools {
+		name := t.Definition.Name
+		x.groups[name] = t.Group
+		x.builtin[t.Group] = append(x.builtin[t.Group], name)
+		if t.Group == SecretsGroup {
+			continue
+		}
+		x.builtin["*"] = append(x.builtin["*"], name)
+		if IsReadOnly(name) {
+			x.builtin["read"] = append(x.builtin["read"], name)
+		} else {
+			x.builtin["write"] = append(x.builtin["write"], name)
+		}
+	}
+	return x
+}
+
+// All returns every indexed tool.
+func (x *ToolIndex) All() map[string]bool {
+	set := make(map[string]bool, len(x.groups))
+	for name := range x.groups {
+		set[name] = true
+	}
+	return set
+}
+
+// Group returns the group of the named tool.
+func (x *ToolIndex) Group(tool string) string {
+	return x.groups[tool]
+}
+
+// Resolve expands entries into a set of tool names. Entries may name tools,
+// built-in groups or groups declared in custom.
+func (x *ToolIndex) Resolve(entries []string, custom map[string][]string) (map[string]bool, error) {
+	set := make(map[string]bool)
+	for _, entry := range entries {
+		switch {
+		case x.groups[entry] != "":
+			set[entry] = true
+		case custom[entry] != nil:
+			for _, name := range custom[entry] {
+				if x.groups[name] == "" {
+					return nil, fmt.Errorf("group %q: unknown tool %q", entry, name)
+				}
+				set[name] = true
+			}
+		case x.builtin[entry] != nil:
+			for _, name := range x.builtin[entry] {
+				set[name] = true
+			}
+		default:
+			return nil, fmt.Errorf("unknown tool or group %q", entry)
+		}
+	}
+	return set, nil
+}
@@ -9,10 +9,12 @@ import (
 	tools_repositories "github.com/docker-hub-api/mcp-server/tools/repositories"
 	tools_access_tokens "github.com/docker-hub-api/mcp-server/tools/access_tokens"
 	tools_org_settings "github.com/docker-hub-api/mcp-server/tools/org_settings"
+	tools_secrets "github.com/docker-hub-api/mcp-server/tools/secrets"
+	"github.com/docker-hub-api/mcp-server/vault"
 )
 
 func GetAll(cfg *config.APIConfig) []models.Tool {
-	return []models.Tool{
+	tools := []models.Tool{
 		tools_audit_logs.CreateAuditlogs_getauditlogsTool(cfg),
 		tools_images.CreateGetnamespacesrepositoriesimagesTool(cfg),
 		tools_authentication.CreatePostusers2faloginTool(cfg),
@@ -32,5 +34,12 @@ func GetAll(cfg *config.APIConfig) []models.Tool {
 		tools_access_tokens.CreateGet_v2_access_tokensTool(cfg),
 		tools_access_tokens.CreatePost_v2_access_tokensTool(cfg),
 		tools_images.CreatePostnamespacesdeleteimagesTool(cfg),
+		tools_authentication.CreateWhoamiTool(cfg),
+		tools_authentication.CreateLogoutTool(cfg),
 	}
+	// reveal_secret exists only when the operator allows revealing vault secrets
+	if vault.Default.RevealEnabled() {
+		tools = append(tools, tools_secrets.CreateReveal_secretTool(cfg))
+	}
+	return tools
 }
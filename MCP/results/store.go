@@ -0,0 +1,256 @@
+// Package results keeps tool results that are too large to return inline
+// and serves them back, in chunks, as MCP resources.
+package results
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// URIPrefix starts the URI of every stored result, e.g.
+// "hub://results/3f2a..."; chunks are read from ".../chunks/2".
+const URIPrefix = "hub://results/"
+
+const chunkPath = "/chunks/"
+
+// Stored is the result store used by tools. main replaces it with one using
+// the configured limits.
+var Stored = NewStore(config.DefaultResultsConfig())
+
+// Result is a stored tool result.
+type Result struct {
+	URI      string
+	Tool     string
+	MIMEType string
+	Size     int
+	Expires  time.Time
+	data     []byte
+	ends     []int  // End offset of each chunk
+	header   string // CSV header repeated at the start of later chunks
+	session  string
+}
+
+// Chunks returns the number of chunks r is read in.
+func (r Result) Chunks() int {
+	return len(r.ends)
+}
+
+// Chunk returns chunk n of r, counting from 1.
+func (r Result) Chunk(n int) []byte {
+	start := 0
+	if n > 1 {
+		start = r.ends[n-2]
+	}
+	chunk := r.data[start:r.ends[n-1]]
+	if n > 1 && r.header != "" {
+		return append([]byte(r.header), chunk...)
+	}
+	return chunk
+}
+
+// ChunkURI returns the URI chunk n of r is read from.
+func (r Result) ChunkURI(n int) string {
+	return r.URI + chunkPath + strconv.Itoa(n)
+}
+
+// Describe says how large r is, how to read it and until when.
+func (r Result) Describe() string {
+	if r.Chunks() == 1 {
+		return fmt.Sprintf("Stored as %s (%s, %d bytes) until %s; read it with resources/read.", r.URI, r.MIMEType, r.Size, r.Expires.UTC().Format(time.RFC3339))
+	}
+	return fmt.Sprintf("Stored as %s (%s, %d bytes) until %s; read it with resources/read in %d chunks from %s to %s.",
+		r.URI, r.MIMEType, r.Size, r.Expires.UTC().Format(time.RFC3339), r.Chunks(), r.ChunkURI(1), r.ChunkURI(r.Chunks()))
+}
+
+// Link returns resource_link content pointing at r.
+func (r Result) Link(description string) mcp.ResourceLink {
+	return mcp.NewResourceLink(r.URI, r.Tool+" result", description, r.MIMEType)
+}
+
+// Store holds results until they expire, dropping the oldest once their
+// total size exceeds its limit. Results can only be read from the MCP
+// session that stored them.
+type Store struct {
+	mu      sync.Mutex
+	cfg     config.ResultsConfig
+	results map[string]Result
+	order   []string // IDs, oldest first
+	size    int
+	now     func() time.Time
+}
+
+// NewStore returns a store with the limits of cfg.
+func NewStore(cfg *config.ResultsConfig) *Store {
+	return &Store{cfg: *cfg, results: make(map[string]Result), now: time.Now}
+}
+
+// InlineBytes is the longest result tools return inline; 0 means no limit.
+func (s *Store) InlineBytes() int {
+	return s.cfg.InlineBytes
+}
+
+// Put stores data produced by tool for the MCP session in ctx.
+func (s *Store) Put(ctx context.Context, tool, mimeType string, data []byte) Result {
+	id := newID()
+	r := Result{
+		URI:      URIPrefix + id,
+		Tool:     tool,
+		MIMEType: mimeType,
+		Size:     len(data),
+		Expires:  s.now().Add(s.cfg.TTL),
+		data:     data,
+		ends:     chunkEnds(data, s.cfg.ChunkBytes),
+		session:  sessionID(ctx),
+	}
+	if mimeType == "text/csv" {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			r.header = string(data[:i+1])
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[id] = r
+	s.order = append(s.order, id)
+	s.size += r.Size
+	s.evict()
+	return r
+}
+
+// chunkEnds splits data at line ends into chunks of at most size bytes; a
+// longer line is a chunk of its own.
+func chunkEnds(data []byte, size int) []int {
+	var ends []int
+	start := 0
+	for len(data)-start > size {
+		end := start + size
+		if i := bytes.LastIndexByte(data[start:end], '\n'); i >= 0 {
+			end = start + i + 1
+		} else if i := bytes.IndexByte(data[end:], '\n'); i >= 0 {
+			end += i + 1
+		} else {
+			end = len(data)
+		}
+		ends = append(ends, end)
+		start = end
+	}
+	if start < len(data) || len(ends) == 0 {
+		ends = append(ends, len(data))
+	}
+	return ends
+}
+
+// evict drops expired results and then the oldest while the store is too
+// large, always keeping the newest.
+func (s *Store) evict() {
+	now := s.now()
+	kept := s.order[:0]
+	for i, id := range s.order {
+		r := s.results[id]
+		if now.After(r.Expires) || s.size > s.cfg.MaxBytes && i < len(s.order)-1 {
+			delete(s.results, id)
+			s.size -= r.Size
+			continue
+		}
+		kept = append(kept, id)
+	}
+	s.order = kept
+}
+
+// Get returns the result stored under uri, which may name a chunk, for the
+// MCP session in ctx, and the chunk to read.
+func (s *Store) Get(ctx context.Context, uri string) (Result, int, bool) {
+	id, chunk := strings.TrimPrefix(uri, URIPrefix), 1
+	if i := strings.Index(id, chunkPath); i >= 0 {
+		n, err := strconv.Atoi(id[i+len(chunkPath):])
+		if err != nil {
+			return Result{}, 0, false
+		}
+		id, chunk = id[:i], n
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict()
+	r, ok := s.results[id]
+	if !ok || r.session != sessionID(ctx) || chunk < 1 || chunk > r.Chunks() {
+		return Result{}, 0, false
+	}
+	return r, chunk, true
+}
+
+// Forget drops the results of the session with the given ID, e.g. when it ends.
+func (s *Store) Forget(session string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.order[:0]
+	for _, id := range s.order {
+		if r := s.results[id]; r.session == session {
+			delete(s.results, id)
+			s.size -= r.Size
+			continue
+		}
+		kept = append(kept, id)
+	}
+	s.order = kept
+}
+
+// Templates are the resource templates stored results are read through:
+// the whole result, or its first chunk when it has several, and each chunk.
+func Templates() []mcp.ResourceTemplate {
+	return []mcp.ResourceTemplate{
+		mcp.NewResourceTemplate(URIPrefix+"{id}", "Stored tool result",
+			mcp.WithTemplateDescription("Output of a tool call too large to return inline; a result read in chunks returns its first chunk"),
+		),
+		mcp.NewResourceTemplate(URIPrefix+"{id}"+chunkPath+"{chunk}", "Stored tool result chunk",
+			mcp.WithTemplateDescription("One chunk of a stored result, counting from 1; NDJSON and CSV chunks hold whole lines and CSV chunks repeat the header"),
+		),
+	}
+}
+
+// ReadHandler serves resources/read for stored results. The _meta of the
+// contents gives the chunk read, the number of chunks and the next chunk's
+// URI.
+func (s *Store) ReadHandler(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	r, chunk, ok := s.Get(ctx, request.Params.URI)
+	if !ok {
+		return nil, fmt.Errorf("no stored result %s; results can be read for %s by the session that produced them", request.Params.URI, s.cfg.TTL)
+	}
+	meta := map[string]any{
+		"chunk":   chunk,
+		"chunks":  r.Chunks(),
+		"size":    r.Size,
+		"expires": r.Expires.UTC().Format(time.RFC3339),
+	}
+	if chunk < r.Chunks() {
+		meta["next"] = r.ChunkURI(chunk + 1)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		Meta:     meta,
+		URI:      request.Params.URI,
+		MIMEType: r.MIMEType,
+		Text:     string(r.Chunk(chunk)),
+	}}, nil
+}
+
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
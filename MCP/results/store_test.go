@@ -0,0 +1,235 @@
+package results
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// session is an MCP client session known by its ID only.
+type session string
+
+func (s session) Initialize()                                         {}
+func (s session) Initialized() bool                                   { return true }
+func (s session) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s session) SessionID() string                                   { return string(s) }
+
+var srv = server.NewMCPServer("test", "0")
+
+func inSession(id string) context.Context {
+	return srv.WithContext(context.Background(), session(id))
+}
+
+func newTestStore(chunkBytes, maxBytes int) (*Store, *time.Time) {
+	s := NewStore(&config.ResultsConfig{TTL: time.Minute, ChunkBytes: chunkBytes, MaxBytes: maxBytes})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+// read reads uri through the resource handler.
+func read(ctx context.Context, s *Store, uri string) (mcp.TextResourceContents, error) {
+	var request mcp.ReadResourceRequest
+	request.Params.URI = uri
+	contents, err := s.ReadHandler(ctx, request)
+	if err != nil {
+		return mcp.TextResourceContents{}, err
+	}
+	return contents[0].(mcp.TextResourceContents), nil
+}
+
+func TestChunkEnds(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		size int
+		want []int
+	}{
+		{name: "empty", data: "", size: 4, want: []int{0}},
+		{name: "fits", data: "ab\ncd\n", size: 6, want: []int{6}},
+		{name: "splits after a line", data: "ab\ncd\nef\n", size: 7, want: []int{6, 9}},
+		{name: "one line per chunk", data: "ab\ncd\nef\n", size: 3, want: []int{3, 6, 9}},
+		{name: "long line is a chunk of its own", data: "ab\ncdefgh\nij\n", size: 4, want: []int{3, 10, 13}},
+		{name: "no line end", data: "abcdefgh", size: 3, want: []int{8}},
+		{name: "last line without end", data: "ab\ncd", size: 3, want: []int{3, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chunkEnds([]byte(tt.data), tt.size); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chunkEnds = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadChunks(t *testing.T) {
+	tests := []struct {
+		name       string
+		mimeType   string
+		data       string
+		wantChunks []string
+	}{
+		{
+			name:       "csv repeats the header",
+			mimeType:   "text/csv",
+			data:       "name,size\nalpine,3\nbusybox,1\nubuntu,29\n",
+			wantChunks: []string{"name,size\nalpine,3\n", "name,size\nbusybox,1\n", "name,size\nubuntu,29\n"},
+		},
+		{
+			name:       "ndjson",
+			mimeType:   "application/x-ndjson",
+			data:       "{\"name\":\"alpine\"}\n{\"name\":\"busybox\"}\n",
+			wantChunks: []string{"{\"name\":\"alpine\"}\n", "{\"name\":\"busybox\"}\n"},
+		},
+		{
+			name:       "one chunk",
+			mimeType:   "application/json",
+			data:       "{}",
+			wantChunks: []string{"{}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestStore(19, 1<<20)
+			ctx := inSession("a")
+			r := s.Put(ctx, "list_tags", tt.mimeType, []byte(tt.data))
+			if r.Chunks() != len(tt.wantChunks) {
+				t.Fatalf("Chunks = %d, want %d", r.Chunks(), len(tt.wantChunks))
+			}
+
+			// The result's own URI returns the first chunk, whose _meta leads to the rest
+			var got []string
+			for uri := r.URI; uri != ""; {
+				contents, err := read(ctx, s, uri)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if contents.MIMEType != tt.mimeType {
+					t.Errorf("MIMEType = %q, want %q", contents.MIMEType, tt.mimeType)
+				}
+				if contents.Meta["chunk"] != len(got)+1 || contents.Meta["chunks"] != len(tt.wantChunks) || contents.Meta["size"] != len(tt.data) {
+					t.Errorf("_meta = %v", contents.Meta)
+				}
+				got = append(got, contents.Text)
+				uri, _ = contents.Meta["next"].(string)
+				if len(got) > len(tt.wantChunks) {
+					break
+				}
+			}
+			if !reflect.DeepEqual(got, tt.wantChunks) {
+				t.Errorf("chunks = %q, want %q", got, tt.wantChunks)
+			}
+			if contents, err := read(ctx, s, r.ChunkURI(1)); err != nil || contents.Text != tt.wantChunks[0] {
+				t.Errorf("chunk 1 = %q, %v", contents.Text, err)
+			}
+		})
+	}
+}
+
+func TestEviction(t *testing.T) {
+	t.Run("ttl", func(t *testing.T) {
+		s, now := newTestStore(10, 1<<20)
+		ctx := inSession("a")
+		r := s.Put(ctx, "list_tags", "application/json", []byte("{}"))
+		*now = now.Add(time.Minute)
+		if _, err := read(ctx, s, r.URI); err != nil {
+			t.Fatalf("read at expiry: %v", err)
+		}
+		*now = now.Add(time.Second)
+		if _, err := read(ctx, s, r.URI); err == nil {
+			t.Fatal("expired result was read")
+		}
+		if s.size != 0 || len(s.order) != 0 {
+			t.Errorf("size = %d, order = %v after expiry", s.size, s.order)
+		}
+	})
+
+	t.Run("size", func(t *testing.T) {
+		s, _ := newTestStore(10, 10)
+		ctx := inSession("a")
+		first := s.Put(ctx, "list_tags", "text/plain", []byte("12345"))
+		second := s.Put(ctx, "list_tags", "text/plain", []byte("12345"))
+		third := s.Put(ctx, "list_tags", "text/plain", []byte("12345"))
+		for _, tt := range []struct {
+			r    Result
+			kept bool
+		}{{first, false}, {second, true}, {third, true}} {
+			if _, _, ok := s.Get(ctx, tt.r.URI); ok != tt.kept {
+				t.Errorf("%s kept = %v, want %v", tt.r.URI, ok, tt.kept)
+			}
+		}
+
+		// The newest result is kept even when it alone exceeds the limit
+		large := s.Put(ctx, "list_tags", "text/plain", []byte(strings.Repeat("x", 11)))
+		if _, _, ok := s.Get(ctx, large.URI); !ok {
+			t.Error("newest result was dropped")
+		}
+		if len(s.order) != 1 || s.size != 11 {
+			t.Errorf("order = %v, size = %d", s.order, s.size)
+		}
+	})
+}
+
+func TestSessionScoping(t *testing.T) {
+	s, _ := newTestStore(10, 1<<20)
+	r := s.Put(inSession("a"), "list_tags", "application/json", []byte("{}"))
+	other := s.Put(inSession("b"), "list_tags", "application/json", []byte("[]"))
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		uri  string
+		ok   bool
+	}{
+		{name: "same session", ctx: inSession("a"), uri: r.URI, ok: true},
+		{name: "same session, chunk", ctx: inSession("a"), uri: r.ChunkURI(1), ok: true},
+		{name: "other session", ctx: inSession("b"), uri: r.URI},
+		{name: "other session, chunk", ctx: inSession("b"), uri: r.ChunkURI(1)},
+		{name: "no session", ctx: context.Background(), uri: r.URI},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := read(tt.ctx, s, tt.uri); (err == nil) != tt.ok {
+				t.Errorf("read error = %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+
+	s.Forget("a")
+	if _, err := read(inSession("a"), s, r.URI); err == nil {
+		t.Error("result of a forgotten session was read")
+	}
+	if _, err := read(inSession("b"), s, other.URI); err != nil {
+		t.Errorf("result of another session was forgotten: %v", err)
+	}
+}
+
+func TestReadHandlerErrors(t *testing.T) {
+	s, now := newTestStore(3, 1<<20)
+	ctx := inSession("a")
+	r := s.Put(ctx, "list_tags", "application/x-ndjson", []byte("1\n2\n"))
+	expired := s.Put(ctx, "list_tags", "application/json", []byte("{}"))
+	expired.Expires = now.Add(-time.Second)
+	s.results[strings.TrimPrefix(expired.URI, URIPrefix)] = expired
+
+	for name, uri := range map[string]string{
+		"unknown id":       URIPrefix + "0123456789abcdef",
+		"expired":          expired.URI,
+		"chunk 0":          r.ChunkURI(0),
+		"chunk past last":  r.ChunkURI(3),
+		"chunk not number": r.URI + chunkPath + "two",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := read(ctx, s, uri)
+			if err == nil || !strings.Contains(err.Error(), "no stored result "+uri) || !strings.Contains(err.Error(), "1m0s") {
+				t.Errorf("err = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,549 @@
+// Package shape cuts JSON results of read tools down to what the caller
+// asked for: a projection of fields, a summary, and a size budget beyond
+// which the full result is stored as a resource and linked instead. It also
+// renders JSON results of any tool in the format the caller asked for.
+package shape
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/docker-hub-api/mcp-server/cursor"
+	"github.com/docker-hub-api/mcp-server/formatter"
+	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/policy"
+	"github.com/docker-hub-api/mcp-server/results"
+	"github.com/docker-hub-api/mcp-server/times"
+	"github.com/docker-hub-api/mcp-server/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Arguments every read tool accepts; FormatArgument is accepted by every tool
+// returning JSON text.
+const (
+	FieldsArgument   = "fields"
+	MaxBytesArgument = "max_bytes"
+	SummaryArgument  = "summary"
+	FormatArgument   = "format"
+)
+
+// summaryItems is how many items a summary keeps.
+const summaryItems = 5
+
+// recencyFields are the item times summaries rank by, most telling first:
+// when tags, images, tokens or audit log events were last changed.
+var recencyFields = []string{"last_updated", "tag_last_pushed", "last_pushed", "timestamp", "last_used", "created_at"}
+
+// previewBytes bounds the preview returned with a result too large to return
+// inline.
+const previewBytes = 4 << 10
+
+var minBytes = 1.0
+
+var arguments = &validate.Schema{Type: "object", Properties: map[string]*validate.Schema{
+	FieldsArgument: {
+		Type:        "string",
+		Description: "Comma-separated fields to return, dropping all others; [] steps into arrays.",
+		Examples:    []any{"results[].name,results[].digest", "count,results[].name"},
+	},
+	MaxBytesArgument: {
+		Type:        "integer",
+		Description: "Largest result to return, in bytes. A longer result is cut to fit and the full result is linked as a resource.",
+		Examples:    []any{8000},
+		Minimum:     &minBytes,
+	},
+	SummaryArgument: {
+		Type:        "boolean",
+		Description: fmt.Sprintf("Return counts, common values and the %d most recently changed items instead of the full result.", summaryItems),
+		Default:     false,
+	},
+	FormatArgument: {
+		Type:        "string",
+		Description: "Format of the result: json, yaml, a markdown table, or csv or ndjson (one item per line) for export. Tables flatten nested fields and show the most telling columns unless fields is given.",
+		Enum:        formatter.Formats,
+		Default:     formatter.JSON,
+	},
+}}
+
+// options are the shaping arguments of one call.
+type options struct {
+	fields   string
+	maxBytes int64
+	summary  bool
+	format   string
+}
+
+func (o options) empty() bool {
+	return o.fields == "" && o.maxBytes == 0 && !o.summary && (o.format == "" || o.format == formatter.JSON)
+}
+
+// Accepted returns the shaping arguments tool accepts: all of them when it
+// only reads from the API, only FormatArgument when it writes, and none when
+// it declares structured output.
+func Accepted(tool mcp.Tool) []string {
+	switch {
+	case tool.OutputSchema.Type != "":
+		return nil
+	case policy.IsReadOnly(tool.Name):
+		return []string{FieldsArgument, FormatArgument, MaxBytesArgument, SummaryArgument}
+	}
+	return []string{FormatArgument}
+}
+
+// Describe adds the shaping arguments tool accepts to its input schema.
+func Describe(tool *mcp.Tool) {
+	names := Accepted(*tool)
+	if len(names) == 0 {
+		return
+	}
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = make(map[string]any)
+	}
+	for _, name := range names {
+		tool.InputSchema.Properties[name] = arguments.Properties[name].JSONSchema()
+	}
+}
+
+// Middleware returns a middleware that applies the shaping arguments of
+// tools. The arguments are removed before the handler runs.
+func Middleware(tools []models.Tool) server.ToolHandlerMiddleware {
+	accepted := make(map[string][]string)
+	for _, tool := range tools {
+		accepted[tool.Definition.Name] = Accepted(tool.Definition)
+	}
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args, _ := request.Params.Arguments.(map[string]any)
+			names := accepted[request.Params.Name]
+			if len(names) == 0 || args == nil {
+				return next(ctx, request)
+			}
+			opts, rest, errs := parseOptions(args, names)
+			if errs != nil {
+				return errs.Result(), nil
+			}
+			request.Params.Arguments = rest
+			result, err := next(ctx, request)
+			if err != nil || result == nil || result.IsError || result.StructuredContent != nil || opts.empty() && !oversized(result) {
+				return result, err
+			}
+			return apply(ctx, request.Params.Name, result, opts), nil
+		}
+	}
+}
+
+// parseOptions splits the named shaping arguments off args.
+func parseOptions(args map[string]any, names []string) (options, map[string]any, validate.Errors) {
+	picked := make(map[string]any)
+	rest := make(map[string]any, len(args))
+	for name, value := range args {
+		if contains(names, name) {
+			picked[name] = value
+			continue
+		}
+		rest[name] = value
+	}
+	coerced, errs := validate.Arguments(validate.Operation{Args: arguments}, picked, time.UTC)
+	if errs != nil {
+		return options{}, nil, errs
+	}
+	var opts options
+	opts.fields, _ = coerced[FieldsArgument].(string)
+	opts.maxBytes, _ = coerced[MaxBytesArgument].(int64)
+	opts.summary, _ = coerced[SummaryArgument].(bool)
+	opts.format, _ = coerced[FormatArgument].(string)
+	return opts, rest, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// apply shapes the first JSON text content of result.
+func apply(ctx context.Context, tool string, result *mcp.CallToolResult, opts options) *mcp.CallToolResult {
+	for i, content := range result.Content {
+		text, ok := content.(mcp.TextContent)
+		if !ok {
+			continue
+		}
+		decoder := json.NewDecoder(strings.NewReader(text.Text))
+		decoder.UseNumber()
+		var v any
+		if decoder.Decode(&v) != nil {
+			continue
+		}
+		if opts.fields != "" {
+			projected, err := Project(v, opts.fields)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error())
+			}
+			v = projected
+		}
+		if opts.summary {
+			v = Summarize(v)
+		}
+		// Tables show a model's default columns unless fields chose them
+		model := formatter.ModelFor(tool)
+		if opts.fields != "" || opts.summary {
+			model = ""
+		}
+		render := func(v any) ([]byte, error) { return formatter.Render(v, opts.format, model) }
+		data, err := render(v)
+		if err != nil {
+			return result
+		}
+		// max_bytes cuts the result to the caller's budget; without it, results
+		// beyond the store's inline limit are stored with a preview
+		inline := results.Stored.InlineBytes()
+		var budget int
+		switch {
+		case opts.maxBytes > 0 && int64(len(data)) > opts.maxBytes:
+			budget = int(opts.maxBytes)
+		case opts.maxBytes == 0 && inline > 0 && len(data) > inline:
+			budget = min(previewBytes, inline)
+		default:
+			text.Text = string(data)
+			result.Content[i] = text
+			return withLeftOut(result, i, v, opts.format)
+		}
+		stored := results.Stored.Put(ctx, tool, formatter.MIMEType(opts.format), data)
+		truncated, note := Truncate(v, data, budget, render)
+		if opts.maxBytes == 0 {
+			note = "Too large to return inline; this is a preview. " + note
+		}
+		note = fmt.Sprintf("%s %s Narrow it with %s or %s, or use %s=%s or %s for exports read in chunks.",
+			note, stored.Describe(), FieldsArgument, SummaryArgument, FormatArgument, formatter.NDJSON, formatter.CSV)
+		text.Text = truncated
+		shaped := append([]mcp.Content{}, result.Content[:i]...)
+		shaped = append(shaped, text, mcp.NewTextContent(note), stored.Link(note))
+		result.Content = append(shaped, result.Content[i+1:]...)
+		return withLeftOut(result, i, v, opts.format)
+	}
+	return result
+}
+
+// withLeftOut adds the fields the format left out of content i, such as
+// count and next_cursor, as text content after it.
+func withLeftOut(result *mcp.CallToolResult, i int, v any, format string) *mcp.CallToolResult {
+	leftOut := formatter.LeftOut(v, format)
+	if leftOut == "" {
+		return result
+	}
+	content := append([]mcp.Content{}, result.Content[:i+1]...)
+	content = append(content, mcp.NewTextContent(leftOut))
+	result.Content = append(content, result.Content[i+1:]...)
+	return result
+}
+
+// oversized reports whether result has text beyond the store's inline limit.
+func oversized(result *mcp.CallToolResult) bool {
+	inline := results.Stored.InlineBytes()
+	if inline == 0 {
+		return false
+	}
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok && len(text.Text) > inline {
+			return true
+		}
+	}
+	return false
+}
+
+// selection is a tree of projected field names; a nil selection keeps the
+// whole value.
+type selection map[string]selection
+
+// Project keeps only the comma-separated fields of v, such as
+// "count,results[].name". A leading "$." is ignored and "[]" or "[*]" steps
+// into an array; arrays are also stepped into without it. The "_relative"
+// companion of a kept time field is kept with it, and so are pagination
+// cursors.
+func Project(v any, fields string) (any, error) {
+	root := selection{}
+	for _, path := range strings.Split(fields, ",") {
+		path = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(path), "$"), ".")
+		node := root
+		segments := strings.Split(path, ".")
+		for i, segment := range segments {
+			segment = strings.TrimSuffix(strings.TrimSuffix(segment, "[*]"), "[]")
+			if segment == "" {
+				continue
+			}
+			child, exists := node[segment]
+			if exists && child == nil {
+				break
+			}
+			if i == len(segments)-1 {
+				node[segment] = nil
+				break
+			}
+			if !exists {
+				child = selection{}
+				node[segment] = child
+			}
+			node = child
+		}
+	}
+	if len(root) == 0 {
+		return v, nil
+	}
+	matched := 0
+	projected := project(v, root, &matched)
+	if matched == 0 {
+		return nil, fmt.Errorf("%s matched no fields of the result; available fields include %s", FieldsArgument, strings.Join(fieldNames(v), ", "))
+	}
+	if obj, ok := v.(map[string]any); ok {
+		for _, field := range cursor.Fields {
+			if value, ok := obj[field]; ok {
+				projected.(map[string]any)[field] = value
+			}
+		}
+	}
+	return projected, nil
+}
+
+func project(v any, sel selection, matched *int) any {
+	if sel == nil {
+		return v
+	}
+	switch value := v.(type) {
+	case []any:
+		out := make([]any, len(value))
+		for i, item := range value {
+			out[i] = project(item, sel, matched)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(sel))
+		for key, sub := range sel {
+			field, ok := value[key]
+			if !ok {
+				continue
+			}
+			*matched++
+			out[key] = project(field, sub, matched)
+			if relative, ok := value[key+times.RelativeSuffix]; ok && sub == nil {
+				out[key+times.RelativeSuffix] = relative
+			}
+		}
+		return out
+	}
+	return v
+}
+
+// fieldNames lists the top-level fields of v and those of the first item of
+// its main list, for error messages.
+func fieldNames(v any) []string {
+	var names []string
+	if obj, ok := v.(map[string]any); ok {
+		for key := range obj {
+			names = append(names, key)
+		}
+	}
+	if list, key := formatter.List(v); len(list) > 0 {
+		if item, ok := list[0].(map[string]any); ok {
+			for field := range item {
+				names = append(names, key+"[]."+field)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Summarize describes v instead of returning it: the scalar fields of the
+// result (such as count and next), how many items were returned, how often
+// repeated values occur across items (such as tag_status), and the scalar
+// fields of the most relevant items. Items are ranked by the first of
+// recencyFields they carry, newest first, and otherwise kept in the order the
+// API returned them; "ranked_by" names the field. Nested arrays are replaced
+// by their length.
+func Summarize(v any) any {
+	list, key := formatter.List(v)
+	if list == nil {
+		if obj, ok := v.(map[string]any); ok {
+			return scalars(obj)
+		}
+		return v
+	}
+	out := map[string]any{}
+	if obj, ok := v.(map[string]any); ok {
+		for name, field := range scalars(obj) {
+			if name != key+"_count" {
+				out[name] = field
+			}
+		}
+	}
+	out["returned"] = len(list)
+	if counts := valueCounts(list); len(counts) > 0 {
+		// Plain maps, so formats flatten them like decoded JSON
+		values := make(map[string]any, len(counts))
+		for key, byValue := range counts {
+			fieldCounts := make(map[string]any, len(byValue))
+			for value, n := range byValue {
+				fieldCounts[value] = n
+			}
+			values[key] = fieldCounts
+		}
+		out["values"] = values
+	}
+	ranked, field := mostRecent(list)
+	if field != "" {
+		out["ranked_by"] = field
+	}
+	items := make([]any, 0, summaryItems)
+	for _, item := range ranked[:min(len(ranked), summaryItems)] {
+		if obj, ok := item.(map[string]any); ok {
+			items = append(items, scalars(obj))
+			continue
+		}
+		items = append(items, item)
+	}
+	out["items"] = items
+	return out
+}
+
+// mostRecent returns list ordered by the first of recencyFields its items
+// carry as a timestamp, newest first, and that field. Items without it follow
+// in their original order. Without such a field list is returned unchanged.
+func mostRecent(list []any) ([]any, string) {
+	stamp := func(item any, field string) (time.Time, bool) {
+		obj, _ := item.(map[string]any)
+		s, _ := obj[field].(string)
+		t, err := time.Parse(time.RFC3339, s)
+		return t, err == nil
+	}
+	for _, field := range recencyFields {
+		found := false
+		for _, item := range list {
+			if _, found = stamp(item, field); found {
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		ranked := append([]any{}, list...)
+		sort.SliceStable(ranked, func(i, j int) bool {
+			ti, iok := stamp(ranked[i], field)
+			tj, jok := stamp(ranked[j], field)
+			if iok != jok {
+				return iok
+			}
+			return ti.After(tj)
+		})
+		return ranked, field
+	}
+	return list, ""
+}
+
+// scalars keeps the scalar fields of obj and replaces arrays with a
+// "<field>_count" field. Nested objects are dropped.
+func scalars(obj map[string]any) map[string]any {
+	out := make(map[string]any, len(obj))
+	for key, field := range obj {
+		switch value := field.(type) {
+		case []any:
+			out[key+"_count"] = len(value)
+		case map[string]any:
+		default:
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// valueCounts counts the values of string and boolean item fields that take
+// a few values repeatedly, such as status or is_active.
+func valueCounts(list []any) map[string]map[string]int {
+	counts := make(map[string]map[string]int)
+	for _, item := range list {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		for key, field := range obj {
+			if strings.HasSuffix(key, times.RelativeSuffix) {
+				continue
+			}
+			var value string
+			switch f := field.(type) {
+			case string:
+				value = f
+			case bool:
+				value = fmt.Sprint(f)
+			default:
+				continue
+			}
+			if counts[key] == nil {
+				counts[key] = make(map[string]int)
+			}
+			counts[key][value]++
+		}
+	}
+	for key, values := range counts {
+		repeated := false
+		for _, n := range values {
+			repeated = repeated || n > 1
+		}
+		if len(values) > 10 || !repeated {
+			delete(counts, key)
+		}
+	}
+	return counts
+}
+
+// Truncate fits v, rendered as data, into maxBytes. It drops items from the
+// end of the main list while the rest renders too long, so the text stays
+// well-formed; without a list that fits, it cuts data at maxBytes. The note
+// says what was left out.
+func Truncate(v any, data []byte, maxBytes int, render func(any) ([]byte, error)) (string, string) {
+	list, key := formatter.List(v)
+	encode := func(n int) []byte {
+		var shortened any = list[:n]
+		if obj, ok := v.(map[string]any); ok {
+			copied := make(map[string]any, len(obj))
+			for k, field := range obj {
+				copied[k] = field
+			}
+			copied[key] = list[:n]
+			shortened = copied
+		}
+		out, _ := render(shortened)
+		return out
+	}
+	if list != nil && len(encode(0)) <= maxBytes {
+		// The largest n whose encoding fits
+		lo, hi := 0, len(list)
+		for lo < hi {
+			mid := (lo + hi + 1) / 2
+			if len(encode(mid)) <= maxBytes {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		}
+		out := encode(lo)
+		name := "items"
+		if key != "" {
+			name = key
+		}
+		return string(out), fmt.Sprintf("Truncated to %d of %d %s (%d of %d bytes).", lo, len(list), name, len(out), len(data))
+	}
+	cut := data[:maxBytes]
+	for len(cut) > 0 && !utf8.Valid(cut) {
+		cut = cut[:len(cut)-1]
+	}
+	return string(cut), fmt.Sprintf("Cut at %d of %d bytes, mid-value.", len(cut), len(data))
+}
@@ -0,0 +1,346 @@
+package shape
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// decode reads s like apply does, keeping numbers as json.Number.
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		t.Fatalf("decode %s: %v", s, err)
+	}
+	return v
+}
+
+const tags = `{
+	"count": 3,
+	"next_cursor": "c2",
+	"results": [
+		{"name": "latest", "tag_status": "active", "last_updated": "2026-10-16T12:00:00Z", "last_updated_relative": "just now", "images": [{"architecture": "amd64", "digest": "sha256:1"}]},
+		{"name": "v2", "tag_status": "active", "last_updated": "2026-10-10T12:00:00Z", "last_updated_relative": "6 days ago", "images": [{"architecture": "arm64", "digest": "sha256:2"}]},
+		{"name": "v1", "tag_status": "inactive", "last_updated": "2025-01-01T00:00:00Z", "last_updated_relative": "1 year ago", "images": []}
+	]
+}`
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "item field",
+			fields: "results[].name",
+			want:   `{"next_cursor": "c2", "results": [{"name": "latest"}, {"name": "v2"}, {"name": "v1"}]}`,
+		},
+		{
+			name:   "arrays stepped into without []",
+			fields: "count, results.name",
+			want:   `{"count": 3, "next_cursor": "c2", "results": [{"name": "latest"}, {"name": "v2"}, {"name": "v1"}]}`,
+		},
+		{
+			name:   "relative time kept with its field",
+			fields: "$.results[*].last_updated",
+			want: `{"next_cursor": "c2", "results": [
+				{"last_updated": "2026-10-16T12:00:00Z", "last_updated_relative": "just now"},
+				{"last_updated": "2026-10-10T12:00:00Z", "last_updated_relative": "6 days ago"},
+				{"last_updated": "2025-01-01T00:00:00Z", "last_updated_relative": "1 year ago"}]}`,
+		},
+		{
+			name:   "nested arrays",
+			fields: "results[].images[].architecture",
+			want:   `{"next_cursor": "c2", "results": [{"images": [{"architecture": "amd64"}]}, {"images": [{"architecture": "arm64"}]}, {"images": []}]}`,
+		},
+		{
+			name:   "whole field wins over its parts",
+			fields: "results,results[].name",
+			want:   strings.Replace(tags, `"count": 3,`, "", 1),
+		},
+		{
+			name:   "empty keeps everything",
+			fields: " , ",
+			want:   tags,
+		},
+		{
+			name:    "no match",
+			fields:  "size",
+			wantErr: "available fields include count, next_cursor, results, results[].images",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Project(decode(t, tags), tt.fields)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := decode(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "list result",
+			in:   tags,
+			want: `{
+				"count": 3, "next_cursor": "c2", "returned": 3, "ranked_by": "last_updated",
+				"values": {"tag_status": {"active": 2, "inactive": 1}},
+				"items": [
+					{"name": "latest", "tag_status": "active", "last_updated": "2026-10-16T12:00:00Z", "last_updated_relative": "just now", "images_count": 1},
+					{"name": "v2", "tag_status": "active", "last_updated": "2026-10-10T12:00:00Z", "last_updated_relative": "6 days ago", "images_count": 1},
+					{"name": "v1", "tag_status": "inactive", "last_updated": "2025-01-01T00:00:00Z", "last_updated_relative": "1 year ago", "images_count": 0}
+				]
+			}`,
+		},
+		{
+			name: "most recent items",
+			in: `{"logs": [
+				{"action": "a", "timestamp": "2026-10-01T00:00:00Z"},
+				{"action": "b"},
+				{"action": "c", "timestamp": "2026-10-03T02:00:00+02:00"},
+				{"action": "d", "timestamp": "2026-10-05T00:00:00Z"},
+				{"action": "e", "timestamp": "2026-10-02T00:00:00Z"},
+				{"action": "f", "timestamp": "2026-10-04T00:00:00Z"},
+				{"action": "g"}
+			]}`,
+			want: `{"returned": 7, "ranked_by": "timestamp", "items": [
+				{"action": "d", "timestamp": "2026-10-05T00:00:00Z"},
+				{"action": "f", "timestamp": "2026-10-04T00:00:00Z"},
+				{"action": "c", "timestamp": "2026-10-03T02:00:00+02:00"},
+				{"action": "e", "timestamp": "2026-10-02T00:00:00Z"},
+				{"action": "a", "timestamp": "2026-10-01T00:00:00Z"}
+			]}`,
+		},
+		{
+			name: "preferred field wins",
+			in:   `[{"n": 1, "created_at": "2026-10-09T00:00:00Z", "last_updated": "2026-01-01T00:00:00Z"}, {"n": 2, "created_at": "2026-01-01T00:00:00Z", "last_updated": "2026-10-01T00:00:00Z"}]`,
+			want: `{"returned": 2, "ranked_by": "last_updated", "items": [
+				{"n": 2, "created_at": "2026-01-01T00:00:00Z", "last_updated": "2026-10-01T00:00:00Z"},
+				{"n": 1, "created_at": "2026-10-09T00:00:00Z", "last_updated": "2026-01-01T00:00:00Z"}
+			]}`,
+		},
+		{
+			name: "first items without times",
+			in:   `[1, 2, 3, 4, 5, 6, 7]`,
+			want: `{"returned": 7, "items": [1, 2, 3, 4, 5]}`,
+		},
+		{
+			name: "object without a list",
+			in:   `{"name": "acme", "settings": {"enabled": true}, "seats": 5}`,
+			want: `{"name": "acme", "seats": 5}`,
+		},
+		{
+			name: "scalar",
+			in:   `"ok"`,
+			want: `"ok"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(Summarize(decode(t, tt.in)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := decode(t, string(got)), decode(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	render := func(v any) ([]byte, error) { return json.Marshal(v) }
+	tests := []struct {
+		name     string
+		in       string
+		maxBytes int
+		want     string
+		wantNote string
+	}{
+		{
+			name:     "drops items from the end",
+			in:       `{"count": 3, "results": ["aaaa", "bbbb", "cccc"]}`,
+			maxBytes: 35,
+			want:     `{"count":3,"results":["aaaa"]}`,
+			wantNote: "Truncated to 1 of 3 results",
+		},
+		{
+			name:     "top-level list",
+			in:       `["aaaa", "bbbb", "cccc"]`,
+			maxBytes: 15,
+			want:     `["aaaa","bbbb"]`,
+			wantNote: "Truncated to 2 of 3 items",
+		},
+		{
+			name:     "cut mid-value when no list fits",
+			in:       `{"description": "ééééé"}`,
+			maxBytes: 20,
+			want:     `{"description":"éé`,
+			wantNote: "Cut at 20 of 28 bytes, mid-value.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := decode(t, tt.in)
+			data, _ := render(v)
+			got, note := Truncate(v, data, tt.maxBytes, render)
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if len(got) > tt.maxBytes || !utf8.ValidString(got) {
+				t.Errorf("%q does not fit %d bytes of UTF-8", got, tt.maxBytes)
+			}
+			if !strings.Contains(note, tt.wantNote) {
+				t.Errorf("note = %q, want %q", note, tt.wantNote)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	read := models.Tool{Definition: mcp.NewTool("get_v2_namespaces_namespace_repositories_repository_tags")}
+	write := models.Tool{Definition: mcp.NewTool("post_v2_access-tokens")}
+	tests := []struct {
+		name      string
+		tool      string
+		args      map[string]any
+		wantArgs  []string // Arguments the handler sees
+		wantText  string   // Prefix of the first content
+		wantError bool
+	}{
+		{
+			name:     "no shaping",
+			tool:     read.Definition.Name,
+			args:     map[string]any{"namespace": "acme"},
+			wantArgs: []string{"namespace"},
+			wantText: "{\n\t\"count\": 3",
+		},
+		{
+			name:     "fields removed before the handler",
+			tool:     read.Definition.Name,
+			args:     map[string]any{"namespace": "acme", FieldsArgument: "count"},
+			wantArgs: []string{"namespace"},
+			wantText: "{\n  \"count\": 3,\n  \"next_cursor\": \"c2\"\n}",
+		},
+		{
+			name:     "summary as a string",
+			tool:     read.Definition.Name,
+			args:     map[string]any{SummaryArgument: "true"},
+			wantArgs: []string{},
+			wantText: "{\n  \"count\": 3,\n  \"items\"",
+		},
+		{
+			name:     "format for a write tool",
+			tool:     write.Definition.Name,
+			args:     map[string]any{FormatArgument: "yaml"},
+			wantArgs: []string{},
+			wantText: "count: 3\n",
+		},
+		{
+			name:     "read arguments not accepted by a write tool",
+			tool:     write.Definition.Name,
+			args:     map[string]any{FieldsArgument: "count"},
+			wantArgs: []string{FieldsArgument},
+			wantText: "{\n\t\"count\": 3",
+		},
+		{
+			name:      "invalid format",
+			tool:      read.Definition.Name,
+			args:      map[string]any{FormatArgument: "xml"},
+			wantError: true,
+		},
+		{
+			name:      "invalid max_bytes",
+			tool:      read.Definition.Name,
+			args:      map[string]any{MaxBytesArgument: 0},
+			wantError: true,
+		},
+		{
+			name:      "fields matching nothing",
+			tool:      read.Definition.Name,
+			args:      map[string]any{FieldsArgument: "missing"},
+			wantArgs:  []string{},
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen []string
+			handler := Middleware([]models.Tool{read, write})(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				seen = []string{}
+				for name := range request.Params.Arguments.(map[string]any) {
+					seen = append(seen, name)
+				}
+				return mcp.NewToolResultText(tags), nil
+			})
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tt.tool
+			request.Params.Arguments = tt.args
+			result, err := handler(context.Background(), request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.IsError != tt.wantError {
+				t.Fatalf("IsError = %v, want %v: %v", result.IsError, tt.wantError, result.Content)
+			}
+			if tt.wantArgs != nil && !reflect.DeepEqual(seen, tt.wantArgs) {
+				t.Errorf("handler arguments = %v, want %v", seen, tt.wantArgs)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !tt.wantError && !strings.HasPrefix(text, tt.wantText) {
+				t.Errorf("text = %q, want prefix %q", text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestMiddlewareMaxBytes(t *testing.T) {
+	read := models.Tool{Definition: mcp.NewTool("get_v2_namespaces_namespace_repositories_repository_tags")}
+	handler := Middleware([]models.Tool{read})(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(tags), nil
+	})
+	request := mcp.CallToolRequest{}
+	request.Params.Name = read.Definition.Name
+	request.Params.Arguments = map[string]any{MaxBytesArgument: 200}
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Content) != 3 {
+		t.Fatalf("got %d contents, want the truncated result, a note and a resource link", len(result.Content))
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; len(text) > 200 {
+		t.Errorf("result is %d bytes, want at most 200", len(text))
+	}
+	if note := result.Content[1].(mcp.TextContent).Text; !strings.Contains(note, "Truncated to") || !strings.Contains(note, "hub://results/") {
+		t.Errorf("note = %q", note)
+	}
+	if _, ok := result.Content[2].(mcp.ResourceLink); !ok {
+		t.Errorf("content 2 is %T, want a resource link", result.Content[2])
+	}
+}
@@ -0,0 +1,95 @@
+// Package times parses the time expressions tools accept for time-window
+// arguments and renders timestamps in tool results.
+package times
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	// Named zones in TIMEZONE work without a system zoneinfo database
+	_ "time/tzdata"
+)
+
+// Examples lists accepted forms, for error messages and tool descriptions.
+const Examples = "2026-10-01T00:00:00Z, 2026-10-01, 7d, -2h, yesterday or now"
+
+var (
+	relative = regexp.MustCompile(`^([+-]?)((?:\d+(?:w|d|h|m|s))+)$`)
+	spelled  = regexp.MustCompile(`^(\d+)\s*(second|minute|hour|day|week)s?\s+ago$`)
+	part     = regexp.MustCompile(`(\d+)(w|d|h|m|s)`)
+	units    = map[string]time.Duration{
+		"s": time.Second, "second": time.Second,
+		"m": time.Minute, "minute": time.Minute,
+		"h": time.Hour, "hour": time.Hour,
+		"d": 24 * time.Hour, "day": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour,
+	}
+	// Layouts without an offset are read in the configured zone
+	localLayouts = []string{"2006-01-02", "2006-01-02T15:04", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02 15:04:05"}
+)
+
+// Parse reads a point in time relative to now:
+//   - RFC 3339 timestamps, e.g. 2026-10-01T12:00:00+02:00
+//   - dates and times without an offset in loc, e.g. 2026-10-01 or 2026-10-01 12:00
+//   - durations before now, e.g. 7d, -2h or 1w2d ("+2h" is after now)
+//   - "3 days ago", "now", "today", "yesterday" and "tomorrow" (days start at midnight in loc)
+func Parse(s string, now time.Time, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	expr := strings.ToLower(strings.TrimSpace(s))
+	stamp := strings.ToUpper(expr)
+	if t, err := time.Parse(time.RFC3339, stamp); err == nil {
+		return t, nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, stamp, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	midnight := func(days int) time.Time {
+		y, m, d := now.In(loc).Date()
+		return time.Date(y, m, d+days, 0, 0, 0, 0, loc)
+	}
+	switch expr {
+	case "now":
+		return now, nil
+	case "today":
+		return midnight(0), nil
+	case "yesterday":
+		return midnight(-1), nil
+	case "tomorrow":
+		return midnight(1), nil
+	}
+
+	if m := relative.FindStringSubmatch(expr); m != nil {
+		var d time.Duration
+		for _, p := range part.FindAllStringSubmatch(m[2], -1) {
+			n, _ := strconv.Atoi(p[1])
+			d += time.Duration(n) * units[p[2]]
+		}
+		if m[1] == "+" {
+			return now.Add(d), nil
+		}
+		return now.Add(-d), nil
+	}
+	if m := spelled.FindStringSubmatch(expr); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return now.Add(-time.Duration(n) * units[m[2]]), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q; use a form such as %s", s, Examples)
+}
+
+// Normalize parses s like Parse and formats it the way the Hub API expects:
+// RFC 3339 in UTC.
+func Normalize(s string, now time.Time, loc *time.Location) (string, error) {
+	t, err := Parse(s, now, loc)
+	if err != nil {
+		return "", err
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
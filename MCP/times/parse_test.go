@@ -0,0 +1,101 @@
+package times
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 23:30 UTC is already the next day in Berlin
+	now := time.Date(2026, 10, 16, 23, 30, 0, 0, time.UTC)
+	tests := []struct {
+		expr    string
+		loc     *time.Location
+		want    time.Time
+		wantErr bool
+	}{
+		{expr: "2026-10-01T12:00:00Z", want: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)},
+		{expr: "2026-10-01T12:00:00+02:00", loc: berlin, want: time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)},
+		{expr: "2026-10-01t12:00:00z", want: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)},
+		{expr: "2026-10-01", want: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "2026-10-01", loc: berlin, want: time.Date(2026, 9, 30, 22, 0, 0, 0, time.UTC)},
+		{expr: " 2026-10-01 12:00 ", loc: berlin, want: time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)},
+		{expr: "2026-10-01T12:00:30", want: time.Date(2026, 10, 1, 12, 0, 30, 0, time.UTC)},
+		{expr: "now", want: now},
+		{expr: "today", want: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{expr: "today", loc: berlin, want: time.Date(2026, 10, 16, 22, 0, 0, 0, time.UTC)},
+		{expr: "Yesterday", want: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{expr: "tomorrow", want: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{expr: "7d", want: now.Add(-7 * 24 * time.Hour)},
+		{expr: "-2h", want: now.Add(-2 * time.Hour)},
+		{expr: "+2h", want: now.Add(2 * time.Hour)},
+		{expr: "1w2d", want: now.Add(-9 * 24 * time.Hour)},
+		{expr: "90m", want: now.Add(-90 * time.Minute)},
+		{expr: "1h30m15s", want: now.Add(-(time.Hour + 30*time.Minute + 15*time.Second))},
+		{expr: "3 days ago", want: now.Add(-3 * 24 * time.Hour)},
+		{expr: "1 week ago", want: now.Add(-7 * 24 * time.Hour)},
+		{expr: "45 minutes ago", want: now.Add(-45 * time.Minute)},
+		{expr: "", wantErr: true},
+		{expr: "last tuesday", wantErr: true},
+		{expr: "7", wantErr: true},
+		{expr: "7y", wantErr: true},
+		{expr: "3 days", wantErr: true},
+		{expr: "2026-13-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		name := tt.expr
+		if tt.loc != nil {
+			name += " in " + tt.loc.String()
+		}
+		t.Run(name, func(t *testing.T) {
+			got, err := Parse(tt.expr, now, tt.loc)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), Examples) {
+					t.Fatalf("err = %v, want an error listing the accepted forms", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Parse(%q) = %s, want %s", tt.expr, got.UTC(), tt.want.UTC())
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr    string
+		loc     *time.Location
+		want    string
+		wantErr bool
+	}{
+		{expr: "2026-10-01T12:00:00+02:00", want: "2026-10-01T10:00:00Z"},
+		{expr: "2026-10-01", loc: tokyo, want: "2026-09-30T15:00:00Z"},
+		{expr: "1d", want: "2026-10-15T12:00:00Z"},
+		{expr: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Normalize(tt.expr, now, tt.loc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package times
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// RelativeSuffix is appended to a field name for its relative time.
+const RelativeSuffix = "_relative"
+
+// Relative describes t as seen from now, e.g. "3 days ago" or "in 2 hours".
+func Relative(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	var n int
+	var unit string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		n, unit = int(d/time.Minute), "minute"
+	case d < 48*time.Hour:
+		n, unit = int(d/time.Hour), "hour"
+	case d < 60*24*time.Hour:
+		n, unit = int(d/(24*time.Hour)), "day"
+	case d < 2*365*24*time.Hour:
+		n, unit = int(d/(30*24*time.Hour)), "month"
+	default:
+		n, unit = int(d/(365*24*time.Hour)), "year"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	if future {
+		return fmt.Sprintf("in %d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %s ago", n, unit)
+}
+
+// Annotate rewrites every RFC 3339 timestamp held in an object field of v to
+// loc and adds a sibling "<field>_relative" field, e.g. "last_updated":
+// "2026-10-16T12:00:00+02:00" and "last_updated_relative": "3 days ago".
+func Annotate(v any, now time.Time, loc *time.Location) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, field := range value {
+			s, ok := field.(string)
+			if !ok {
+				value[key] = Annotate(field, now, loc)
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil || strings.HasSuffix(key, RelativeSuffix) {
+				continue
+			}
+			value[key] = t.In(loc).Format(time.RFC3339)
+			if _, exists := value[key+RelativeSuffix]; !exists {
+				value[key+RelativeSuffix] = Relative(t, now)
+			}
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = Annotate(item, now, loc)
+		}
+	}
+	return v
+}
+
+// ResultMiddleware annotates timestamps in JSON tool results, using the
+// timezone of the effective APIConfig. Results with structured content keep
+// their declared shape and are left alone.
+func ResultMiddleware(base *config.APIConfig) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil || result == nil || result.IsError || result.StructuredContent != nil {
+				return result, err
+			}
+			loc := Location(config.FromContext(ctx, base))
+			now := time.Now()
+			for i, content := range result.Content {
+				text, ok := content.(mcp.TextContent)
+				if !ok || !strings.HasPrefix(strings.TrimSpace(text.Text), "{") && !strings.HasPrefix(strings.TrimSpace(text.Text), "[") {
+					continue
+				}
+				// Numbers are kept as written; float64 would mangle large IDs
+				decoder := json.NewDecoder(strings.NewReader(text.Text))
+				decoder.UseNumber()
+				var v any
+				if decoder.Decode(&v) != nil {
+					continue
+				}
+				annotated, err := json.MarshalIndent(Annotate(v, now, loc), "", "  ")
+				if err != nil {
+					continue
+				}
+				text.Text = string(annotated)
+				result.Content[i] = text
+			}
+			return result, nil
+		}
+	}
+}
+
+// Location returns the timezone of cfg, defaulting to UTC.
+func Location(cfg *config.APIConfig) *time.Location {
+	if cfg == nil || cfg.Timezone == nil {
+		return time.UTC
+	}
+	return cfg.Timezone
+}
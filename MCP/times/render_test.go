@@ -0,0 +1,159 @@
+package times
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRelative(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		offset time.Duration // t - now
+		want   string
+	}{
+		{0, "just now"},
+		{-59 * time.Second, "just now"},
+		{-time.Minute, "1 minute ago"},
+		{-45 * time.Minute, "45 minutes ago"},
+		{-time.Hour, "1 hour ago"},
+		{-47 * time.Hour, "47 hours ago"},
+		{-48 * time.Hour, "2 days ago"},
+		{-59 * 24 * time.Hour, "59 days ago"},
+		{-90 * 24 * time.Hour, "3 months ago"},
+		{-3 * 365 * 24 * time.Hour, "3 years ago"},
+		{2 * time.Hour, "in 2 hours"},
+		{24 * time.Hour, "in 24 hours"},
+		{10 * 24 * time.Hour, "in 10 days"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Relative(now.Add(tt.offset), now); got != tt.want {
+				t.Errorf("Relative(now%+v) = %q, want %q", tt.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		in   string
+		loc  *time.Location
+		want string
+	}{
+		{
+			name: "field",
+			in:   `{"last_updated": "2026-10-13T12:00:00Z", "name": "web"}`,
+			loc:  time.UTC,
+			want: `{"last_updated": "2026-10-13T12:00:00Z", "last_updated_relative": "3 days ago", "name": "web"}`,
+		},
+		{
+			name: "in zone",
+			in:   `{"created_at": "2026-10-16T10:00:00Z"}`,
+			loc:  tokyo,
+			want: `{"created_at": "2026-10-16T19:00:00+09:00", "created_at_relative": "2 hours ago"}`,
+		},
+		{
+			name: "nested lists",
+			in:   `{"results": [{"pushed": "2026-10-16T11:30:00Z"}, {"pushed": null}], "count": 2}`,
+			loc:  time.UTC,
+			want: `{"results": [{"pushed": "2026-10-16T11:30:00Z", "pushed_relative": "30 minutes ago"}, {"pushed": null}], "count": 2}`,
+		},
+		{
+			name: "existing relative field kept",
+			in:   `{"at": "2026-10-16T11:00:00Z", "at_relative": "custom"}`,
+			loc:  time.UTC,
+			want: `{"at": "2026-10-16T11:00:00Z", "at_relative": "custom"}`,
+		},
+		{
+			name: "not timestamps",
+			in:   `{"date": "2026-10-16", "tag": "v1", "size": 10}`,
+			loc:  time.UTC,
+			want: `{"date": "2026-10-16", "tag": "v1", "size": 10}`,
+		},
+		{
+			name: "top-level list",
+			in:   `[{"t": "2026-10-16T12:00:00Z"}]`,
+			loc:  time.UTC,
+			want: `[{"t": "2026-10-16T12:00:00Z", "t_relative": "just now"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var in, want any
+			if err := json.Unmarshal([]byte(tt.in), &in); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if got := Annotate(in, now, tt.loc); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestResultMiddleware(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		result *mcp.CallToolResult
+		cfg    *config.APIConfig
+		want   string
+	}{
+		{
+			name:   "JSON in zone of the config",
+			result: mcp.NewToolResultText(`{"at": "2000-01-01T00:00:00Z"}`),
+			cfg:    &config.APIConfig{Timezone: tokyo},
+			want:   "{\n  \"at\": \"2000-01-01T09:00:00+09:00\",\n  \"at_relative\": ",
+		},
+		{
+			name:   "large numbers kept",
+			result: mcp.NewToolResultText(`{"id": 12345678901234567890}`),
+			cfg:    &config.APIConfig{},
+			want:   "{\n  \"id\": 12345678901234567890\n}",
+		},
+		{
+			name:   "plain text untouched",
+			result: mcp.NewToolResultText("at 2000-01-01T00:00:00Z"),
+			cfg:    &config.APIConfig{},
+			want:   "at 2000-01-01T00:00:00Z",
+		},
+		{
+			name:   "errors untouched",
+			result: mcp.NewToolResultError(`{"at": "2000-01-01T00:00:00Z"}`),
+			cfg:    &config.APIConfig{},
+			want:   `{"at": "2000-01-01T00:00:00Z"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := ResultMiddleware(tt.cfg)(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tt.result, nil
+			})
+			result, err := handler(context.Background(), mcp.CallToolRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if len(text) < len(tt.want) || text[:len(tt.want)] != tt.want {
+				t.Errorf("text = %q, want prefix %q", text, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Package tlsutil builds the server-side tls.Config for HTTPS mode and keeps
+// its certificate material current without a restart.
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+// Reloader holds the server certificate and client CA pool loaded from the
+// files named in a config.TLSConfig and reloads them on demand.
+type Reloader struct {
+	cfg *config.TLSConfig
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+// NewReloader loads the certificate, key and optional client CA bundle.
+func NewReloader(cfg *config.TLSConfig) (*Reloader, error) {
+	r := &Reloader{cfg: cfg}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload re-reads all files. On error the previously loaded material stays in use.
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+	var pool *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read TLS_CLIENT_CA_FILE: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("TLS_CLIENT_CA_FILE contains no PEM certificates")
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCA = pool
+	r.modTimes = r.statFiles()
+	return nil
+}
+
+// Changed reports whether any watched file was modified since the last load.
+func (r *Reloader) Changed() bool {
+	current := r.statFiles()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for name, t := range current {
+		if !t.Equal(r.modTimes[name]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reloader) statFiles() map[string]time.Time {
+	times := make(map[string]time.Time)
+	for _, name := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCAFile} {
+		if name == "" {
+			continue
+		}
+		if fi, err := os.Stat(name); err == nil {
+			times[name] = fi.ModTime()
+		}
+	}
+	return times
+}
+
+// Watch reloads when the files change, polling every ReloadInterval, and
+// whenever a value arrives on signals (e.g. SIGHUP). It returns when stop is closed.
+func (r *Reloader) Watch(signals <-chan os.Signal, stop <-chan struct{}) {
+	var tick <-chan time.Time
+	if r.cfg.ReloadInterval > 0 {
+		ticker := time.NewTicker(r.cfg.ReloadInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-stop:
+			return
+		case <-tick:
+			if !r.Changed() {
+				continue
+			}
+			r.reload("certificate files changed")
+		case sig := <-signals:
+			r.reload(fmt.Sprintf("received %s", sig))
+		}
+	}
+}
+
+func (r *Reloader) reload(reason string) {
+	if err := r.Reload(); err != nil {
+		log.Printf("TLS reload (%s) failed, keeping previous certificate: %v", reason, err)
+		return
+	}
+	log.Printf("TLS certificate reloaded (%s)", reason)
+}
+
+// ServerConfig returns a tls.Config that serves the current certificate and
+// verifies client certificates against the current CA pool on every handshake.
+func (r *Reloader) ServerConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion:   r.cfg.MinVersion,
+		CipherSuites: r.cfg.CipherSuites,
+		ClientAuth:   r.cfg.ClientAuth,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	return &tls.Config{
+		MinVersion: r.cfg.MinVersion,
+		NextProtos: base.NextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			c := base.Clone()
+			c.Certificates = []tls.Certificate{*r.cert}
+			c.ClientCAs = r.clientCA
+			return c, nil
+		},
+	}
+}
@@ -0,0 +1,228 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+// keyPair is a certificate and its key, in PEM and parsed.
+type keyPair struct {
+	certPEM, keyPEM []byte
+	cert            *x509.Certificate
+	key             *ecdsa.PrivateKey
+}
+
+// issue returns a certificate for name signed by parent, or self-signed when
+// parent is nil.
+func issue(t *testing.T, name string, parent *keyPair, isCA bool) *keyPair {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &keyPair{
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		cert:    cert,
+		key:     key,
+	}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serverFiles writes a CA and a server certificate it signed to dir and
+// returns a TLSConfig naming them.
+func serverFiles(t *testing.T, dir string) (*config.TLSConfig, *keyPair) {
+	t.Helper()
+	ca := issue(t, "test CA", nil, true)
+	server := issue(t, "127.0.0.1", ca, false)
+	cfg := &config.TLSConfig{
+		CertFile:     filepath.Join(dir, "cert.pem"),
+		KeyFile:      filepath.Join(dir, "key.pem"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+		MinVersion:   tls.VersionTLS12,
+	}
+	writeFile(t, cfg.CertFile, server.certPEM)
+	writeFile(t, cfg.KeyFile, server.keyPEM)
+	writeFile(t, cfg.ClientCAFile, ca.certPEM)
+	return cfg, ca
+}
+
+func TestReloadKeepsPreviousOnError(t *testing.T) {
+	cfg, ca := serverFiles(t, t.TempDir())
+	r, err := NewReloader(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		corrupt func()
+	}{
+		{name: "certificate not PEM", corrupt: func() { writeFile(t, cfg.CertFile, []byte("not a certificate")) }},
+		{name: "key of another certificate", corrupt: func() { writeFile(t, cfg.KeyFile, issue(t, "other", nil, false).keyPEM) }},
+		{name: "certificate missing", corrupt: func() { os.Remove(cfg.CertFile) }},
+		{name: "CA bundle without certificates", corrupt: func() { writeFile(t, cfg.ClientCAFile, []byte("# empty\n")) }},
+		{name: "CA bundle missing", corrupt: func() { os.Remove(cfg.ClientCAFile) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Start each case from good files
+			server := issue(t, "127.0.0.1", ca, false)
+			writeFile(t, cfg.CertFile, server.certPEM)
+			writeFile(t, cfg.KeyFile, server.keyPEM)
+			writeFile(t, cfg.ClientCAFile, ca.certPEM)
+			if err := r.Reload(); err != nil {
+				t.Fatal(err)
+			}
+			loaded := r.cert
+
+			tt.corrupt()
+			if err := r.Reload(); err == nil {
+				t.Fatal("Reload succeeded")
+			}
+			if r.cert != loaded || r.clientCA == nil {
+				t.Error("previous certificate or CA pool was replaced")
+			}
+		})
+	}
+
+	if _, err := NewReloader(&config.TLSConfig{CertFile: cfg.CertFile + ".missing", KeyFile: cfg.KeyFile}); err == nil {
+		t.Error("NewReloader succeeded without a certificate")
+	}
+}
+
+func TestChanged(t *testing.T) {
+	cfg, _ := serverFiles(t, t.TempDir())
+	r, err := NewReloader(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Changed() {
+		t.Fatal("Changed right after loading")
+	}
+	for _, name := range []string{cfg.CertFile, cfg.KeyFile, cfg.ClientCAFile} {
+		later := time.Now().Add(time.Minute)
+		if err := os.Chtimes(name, later, later); err != nil {
+			t.Fatal(err)
+		}
+		if !r.Changed() {
+			t.Errorf("Changed = false after %s was modified", filepath.Base(name))
+		}
+		if err := r.Reload(); err != nil {
+			t.Fatal(err)
+		}
+		if r.Changed() {
+			t.Errorf("Changed = true after reloading %s", filepath.Base(name))
+		}
+	}
+}
+
+func TestServerConfigClientAuth(t *testing.T) {
+	cfg, ca := serverFiles(t, t.TempDir())
+	client := issue(t, "ci-bot", ca, false)
+	clientCert, err := tls.X509KeyPair(client.certPEM, client.keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stranger := issue(t, "stranger", issue(t, "other CA", nil, true), false)
+	strangerCert, err := tls.X509KeyPair(stranger.certPEM, stranger.keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	tests := []struct {
+		name       string
+		clientAuth tls.ClientAuthType
+		cert       *tls.Certificate
+		wantOK     bool
+	}{
+		{name: "required and sent", clientAuth: tls.RequireAndVerifyClientCert, cert: &clientCert, wantOK: true},
+		{name: "required and missing", clientAuth: tls.RequireAndVerifyClientCert},
+		{name: "required and signed by another CA", clientAuth: tls.RequireAndVerifyClientCert, cert: &strangerCert},
+		{name: "optional and missing", clientAuth: tls.VerifyClientCertIfGiven, wantOK: true},
+		{name: "not requested", clientAuth: tls.NoClientCert, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := *cfg
+			c.ClientAuth = tt.clientAuth
+			r, err := NewReloader(&c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			srv.TLS = r.ServerConfig()
+			srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+			srv.StartTLS()
+			defer srv.Close()
+
+			clientTLS := &tls.Config{RootCAs: roots}
+			if tt.cert != nil {
+				clientTLS.Certificates = []tls.Certificate{*tt.cert}
+			}
+			httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+			resp, err := httpClient.Get(srv.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if ok := err == nil && resp.StatusCode == http.StatusNoContent; ok != tt.wantOK {
+				t.Errorf("request succeeded = %v (%v), want %v", ok, err, tt.wantOK)
+			}
+		})
+	}
+}
@@ -6,23 +6,38 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Delete_v2_access_tokens_uuidHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		url := fmt.Sprintf("%s/v2/access-tokens/%s", cfg.BaseURL)
-		req, err := http.NewRequest("DELETE", url, nil)
+		apiCfg := config.FromContext(ctx, cfg)
+		args, ok := request.Params.Arguments.(map[string]any)
+		if !ok {
+			return mcp.NewToolResultError("Invalid arguments object"), nil
+		}
+		uuidVal, ok := args["uuid"]
+		if !ok {
+			return mcp.NewToolResultError("Missing required path parameter: uuid"), nil
+		}
+		uuid, ok := uuidVal.(string)
+		if !ok {
+			return mcp.NewToolResultError("Invalid path parameter: uuid"), nil
+		}
+		url := fmt.Sprintf("%s/v2/access-tokens/%s", apiCfg.BaseURL, url.PathEscape(uuid))
+		req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -34,7 +49,7 @@ func Delete_v2_access_tokens_uuidHandler(cfg *config.APIConfig) func(ctx context
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result map[string]interface{}
@@ -55,10 +70,12 @@ func Delete_v2_access_tokens_uuidHandler(cfg *config.APIConfig) func(ctx context
 func CreateDelete_v2_access_tokens_uuidTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("delete_v2_access-tokens_uuid",
 		mcp.WithDescription("Delete a personal access token"),
+		mcp.WithString("uuid", mcp.Required(), mcp.Description("UUID of the personal access token.")),
 	)
 
 	return models.Tool{
 		Definition: tool,
 		Handler:    Delete_v2_access_tokens_uuidHandler(cfg),
+		Group:      "access_tokens",
 	}
 }
@@ -8,13 +8,16 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Get_v2_access_tokensHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
 			return mcp.NewToolResultError("Invalid arguments object"), nil
@@ -30,15 +33,14 @@ func Get_v2_access_tokensHandler(cfg *config.APIConfig) func(ctx context.Context
 		if len(queryParams) > 0 {
 			queryString = "?" + strings.Join(queryParams, "&")
 		}
-		url := fmt.Sprintf("%s/v2/access-tokens%s", cfg.BaseURL, queryString)
-		req, err := http.NewRequest("GET", url, nil)
+		url := fmt.Sprintf("%s/v2/access-tokens%s", apiCfg.BaseURL, queryString)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -50,7 +52,7 @@ func Get_v2_access_tokensHandler(cfg *config.APIConfig) func(ctx context.Context
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result models.GetAccessTokensResponse
@@ -58,6 +60,9 @@ func Get_v2_access_tokensHandler(cfg *config.APIConfig) func(ctx context.Context
 			// Fallback to raw text if unmarshaling fails
 			return mcp.NewToolResultText(string(body)), nil
 		}
+		// Pagination links must not lead away from the configured API origin
+		result.Next = upstream.SameOriginLink(apiCfg.BaseURL, result.Next)
+		result.Previous = upstream.SameOriginLink(apiCfg.BaseURL, result.Previous)
 
 		prettyJSON, err := json.MarshalIndent(result, "", "  ")
 		if err != nil {
@@ -71,12 +76,13 @@ func Get_v2_access_tokensHandler(cfg *config.APIConfig) func(ctx context.Context
 func CreateGet_v2_access_tokensTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("get_v2_access-tokens",
 		mcp.WithDescription("Get a list of personal access tokens"),
-		mcp.WithString("page", mcp.Description("")),
-		mcp.WithString("page_size", mcp.Description("")),
+		mcp.WithNumber("page", mcp.Description("Page number to get. Defaults to 1.")),
+		mcp.WithNumber("page_size", mcp.Description("Number of tokens to return per page. Defaults to 10.")),
 	)
 
 	return models.Tool{
 		Definition: tool,
 		Handler:    Get_v2_access_tokensHandler(cfg),
+		Group:      "access_tokens",
 	}
 }
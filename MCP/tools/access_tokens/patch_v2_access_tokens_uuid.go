@@ -6,19 +6,31 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"bytes"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Patch_v2_access_tokens_uuidHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
 			return mcp.NewToolResultError("Invalid arguments object"), nil
 		}
+		uuidVal, ok := args["uuid"]
+		if !ok {
+			return mcp.NewToolResultError("Missing required path parameter: uuid"), nil
+		}
+		uuid, ok := uuidVal.(string)
+		if !ok {
+			return mcp.NewToolResultError("Invalid path parameter: uuid"), nil
+		}
 		// Create properly typed request body using the generated schema
 		var requestBody models.PatchAccessTokenRequest
 		
@@ -35,16 +47,15 @@ func Patch_v2_access_tokens_uuidHandler(cfg *config.APIConfig) func(ctx context.
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to encode request body", err), nil
 		}
-		url := fmt.Sprintf("%s/v2/access-tokens/%s", cfg.BaseURL)
-		req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(bodyBytes))
+		url := fmt.Sprintf("%s/v2/access-tokens/%s", apiCfg.BaseURL, url.PathEscape(uuid))
+		req, err := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewBuffer(bodyBytes))
 		req.Header.Set("Content-Type", "application/json")
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -56,7 +67,7 @@ func Patch_v2_access_tokens_uuidHandler(cfg *config.APIConfig) func(ctx context.
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result models.PatchAccessTokenResponse
@@ -77,12 +88,14 @@ func Patch_v2_access_tokens_uuidHandler(cfg *config.APIConfig) func(ctx context.
 func CreatePatch_v2_access_tokens_uuidTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("patch_v2_access-tokens_uuid",
 		mcp.WithDescription("Update a personal access token"),
-		mcp.WithBoolean("is_active", mcp.Description("")),
-		mcp.WithString("token_label", mcp.Description("")),
+		mcp.WithString("uuid", mcp.Required(), mcp.Description("UUID of the personal access token.")),
+		mcp.WithBoolean("is_active", mcp.Description("Set to false to deactivate the token or true to reactivate it.")),
+		mcp.WithString("token_label", mcp.Description("New friendly name for you to identify the token.")),
 	)
 
 	return models.Tool{
 		Definition: tool,
 		Handler:    Patch_v2_access_tokens_uuidHandler(cfg),
+		Group:      "access_tokens",
 	}
 }
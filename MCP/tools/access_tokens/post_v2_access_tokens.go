@@ -8,13 +8,17 @@ import (
 	"net/http"
 	"bytes"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
+	"github.com/docker-hub-api/mcp-server/vault"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Post_v2_access_tokensHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
 			return mcp.NewToolResultError("Invalid arguments object"), nil
@@ -35,16 +39,15 @@ func Post_v2_access_tokensHandler(cfg *config.APIConfig) func(ctx context.Contex
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to encode request body", err), nil
 		}
-		url := fmt.Sprintf("%s/v2/access-tokens", cfg.BaseURL)
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyBytes))
+		url := fmt.Sprintf("%s/v2/access-tokens", apiCfg.BaseURL)
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(bodyBytes))
 		req.Header.Set("Content-Type", "application/json")
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -56,13 +59,21 @@ func Post_v2_access_tokensHandler(cfg *config.APIConfig) func(ctx context.Contex
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result models.CreateAccessTokensResponse
 		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
+			// The raw body would contain the secret, so it is not returned
+			return mcp.NewToolResultErrorFromErr("Failed to parse API response", err), nil
+		}
+		// Keep the plaintext token out of the tool result; it is revealed only via reveal_secret
+		if result.Token != "" {
+			handle, err := vault.Default.Put(ctx, vault.Secret{Value: result.Token, Kind: "access_token", Description: result.Token_label})
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("Failed to store token in vault", err), nil
+			}
+			result.Token = handle
 		}
 
 		prettyJSON, err := json.MarshalIndent(result, "", "  ")
@@ -76,7 +87,7 @@ func Post_v2_access_tokensHandler(cfg *config.APIConfig) func(ctx context.Contex
 
 func CreatePost_v2_access_tokensTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("post_v2_access-tokens",
-		mcp.WithDescription("Create a personal access token"),
+		mcp.WithDescription("Create a personal access token. The token in the result is a vault handle; the plaintext stays on the server and is only available through reveal_secret when enabled."),
 		mcp.WithArray("scopes", mcp.Required(), mcp.Description("Input parameter: Valid scopes: \"repo:admin\", \"repo:write\", \"repo:read\", \"repo:public_read\"\n")),
 		mcp.WithString("token_label", mcp.Required(), mcp.Description("Input parameter: Friendly name for you to identify the token.")),
 	)
@@ -84,5 +95,6 @@ func CreatePost_v2_access_tokensTool(cfg *config.APIConfig) models.Tool {
 	return models.Tool{
 		Definition: tool,
 		Handler:    Post_v2_access_tokensHandler(cfg),
+		Group:      "access_tokens",
 	}
 }
@@ -6,14 +6,18 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Auditlogs_getauditactionsHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
 			return mcp.NewToolResultError("Invalid arguments object"), nil
@@ -26,15 +30,14 @@ func Auditlogs_getauditactionsHandler(cfg *config.APIConfig) func(ctx context.Co
 		if !ok {
 			return mcp.NewToolResultError("Invalid path parameter: account"), nil
 		}
-		url := fmt.Sprintf("%s/v2/auditlogs/%s/actions", cfg.BaseURL, account)
-		req, err := http.NewRequest("GET", url, nil)
+		url := fmt.Sprintf("%s/v2/auditlogs/%s/actions", apiCfg.BaseURL, url.PathEscape(account))
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -46,7 +49,7 @@ func Auditlogs_getauditactionsHandler(cfg *config.APIConfig) func(ctx context.Co
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result models.GetAuditActionsResponse
@@ -73,5 +76,6 @@ func CreateAuditlogs_getauditactionsTool(cfg *config.APIConfig) models.Tool {
 	return models.Tool{
 		Definition: tool,
 		Handler:    Auditlogs_getauditactionsHandler(cfg),
+		Group:      "audit_logs",
 	}
 }
@@ -6,15 +6,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Auditlogs_getauditlogsHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
 			return mcp.NewToolResultError("Invalid arguments object"), nil
@@ -53,15 +57,14 @@ func Auditlogs_getauditlogsHandler(cfg *config.APIConfig) func(ctx context.Conte
 		if len(queryParams) > 0 {
 			queryString = "?" + strings.Join(queryParams, "&")
 		}
-		url := fmt.Sprintf("%s/v2/auditlogs/%s%s", cfg.BaseURL, account, queryString)
-		req, err := http.NewRequest("GET", url, nil)
+		url := fmt.Sprintf("%s/v2/auditlogs/%s%s", apiCfg.BaseURL, url.PathEscape(account), queryString)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -73,7 +76,7 @@ func Auditlogs_getauditlogsHandler(cfg *config.APIConfig) func(ctx context.Conte
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result models.GetAuditLogsResponse
@@ -98,8 +101,8 @@ func CreateAuditlogs_getauditlogsTool(cfg *config.APIConfig) models.Tool {
 		mcp.WithString("action", mcp.Description("action name one of [\"repo.tag.push\", ...]. Optional parameter to filter specific audit log actions.")),
 		mcp.WithString("name", mcp.Description("name. Optional parameter to filter audit log events to a specific name. For repository events, this is the name of the repository. For organization events, this is the name of the organization. For team member events, this is the username of the team member.")),
 		mcp.WithString("actor", mcp.Description("actor name. Optional parameter to filter audit log events to the specific user who triggered the event.")),
-		mcp.WithString("from", mcp.Description("Start of the time window you wish to query audit events for.")),
-		mcp.WithString("to", mcp.Description("End of the time window you wish to query audit events for.")),
+		mcp.WithString("from", mcp.Description("Start of the time window you wish to query audit events for. Accepts RFC 3339, a date (2026-10-01), a duration before now (7d, -2h), or today/yesterday.")),
+		mcp.WithString("to", mcp.Description("End of the time window you wish to query audit events for. Accepts RFC 3339, a date (2026-10-01), a duration before now (7d, -2h), or today/yesterday.")),
 		mcp.WithNumber("page", mcp.Description("page - specify page number. Page number to get.")),
 		mcp.WithNumber("page_size", mcp.Description("page_size - specify page size. Number of events to return per page.")),
 	)
@@ -107,5 +110,6 @@ func CreateAuditlogs_getauditlogsTool(cfg *config.APIConfig) models.Tool {
 	return models.Tool{
 		Definition: tool,
 		Handler:    Auditlogs_getauditlogsHandler(cfg),
+		Group:      "audit_logs",
 	}
 }
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// elicitTimeout bounds how long a login waits for the user to enter a code.
+const elicitTimeout = 5 * time.Minute
+
+// errElicitationUnavailable means the client cannot be asked for input, so the
+// caller falls back to returning the intermediate 2FA token.
+var errElicitationUnavailable = errors.New("client does not support elicitation")
+
+// canElicit reports whether the client of the session in ctx declared the
+// elicitation capability.
+func canElicit(ctx context.Context) bool {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+	if !ok || server.ServerFromContext(ctx) == nil {
+		return false
+	}
+	return session.GetClientCapabilities().Elicitation != nil
+}
+
+// elicitTOTPCode asks the user of the session in ctx for the one-time password
+// of username. ok is false when the user declines or cancels.
+func elicitTOTPCode(ctx context.Context, username string) (code string, ok bool, err error) {
+	if !canElicit(ctx) {
+		return "", false, errElicitationUnavailable
+	}
+	ctx, cancel := context.WithTimeout(ctx, elicitTimeout)
+	defer cancel()
+
+	request := mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: "Docker Hub two-factor authentication for " + username + ": enter the code from your authenticator app.",
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"code": map[string]any{
+						"type":        "string",
+						"title":       "Authentication code",
+						"description": "Time-based one-time password",
+						"minLength":   6,
+						"maxLength":   8,
+					},
+				},
+				"required": []string{"code"},
+			},
+		},
+	}
+	result, err := server.ServerFromContext(ctx).RequestElicitation(ctx, request)
+	if err != nil {
+		return "", false, err
+	}
+	if result.Action != mcp.ElicitationResponseActionAccept {
+		return "", false, nil
+	}
+	content, _ := result.Content.(map[string]any)
+	code, _ = content["code"].(string)
+	if code == "" {
+		return "", false, errors.New("no code was entered")
+	}
+	return code, true, nil
+}
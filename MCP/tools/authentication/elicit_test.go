@@ -0,0 +1,261 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/policy"
+	"github.com/docker-hub-api/mcp-server/vault"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// session is a client session that may declare the elicitation capability and
+// answers elicitation requests with response or err.
+type session struct {
+	capabilities mcp.ClientCapabilities
+	response     *mcp.ElicitationResult
+	err          error
+	asked        int
+}
+
+func (s *session) Initialize()       {}
+func (s *session) Initialized() bool { return true }
+func (s *session) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return make(chan mcp.JSONRPCNotification, 1)
+}
+func (s *session) SessionID() string                              { return "elicit-test" }
+func (s *session) GetClientInfo() mcp.Implementation              { return mcp.Implementation{} }
+func (s *session) SetClientInfo(mcp.Implementation)               {}
+func (s *session) GetClientCapabilities() mcp.ClientCapabilities  { return s.capabilities }
+func (s *session) SetClientCapabilities(c mcp.ClientCapabilities) { s.capabilities = c }
+
+func (s *session) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	s.asked++
+	return s.response, s.err
+}
+
+func elicitingSession(action mcp.ElicitationResponseAction, content any) *session {
+	return &session{
+		capabilities: mcp.ClientCapabilities{Elicitation: &struct{}{}},
+		response:     &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: action, Content: content}},
+	}
+}
+
+// hub answers logins like an account with two-factor authentication and
+// counts the calls of /v2/users/2fa-login.
+func hub(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var twoFactorCalls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/users/login":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"detail": "Require secondary authentication on MFA enabled account", "login_2fa_token": "2fa-token"}`))
+		case "/v2/users/2fa-login":
+			twoFactorCalls.Add(1)
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["code"] != "123456" || body["login_2fa_token"] != "2fa-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"detail": "Incorrect authentication credentials"}`))
+				return
+			}
+			w.Write([]byte(`{"token": "jwt-secret"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &twoFactorCalls
+}
+
+type denyTool string
+
+func (d denyTool) Check(_ context.Context, tool string, _ map[string]any) error {
+	if tool == string(d) {
+		return errors.New("tool " + tool + " is not enabled")
+	}
+	return nil
+}
+
+// login calls post_v2_users_login through an MCP server, as a client in s would.
+func login(t *testing.T, baseURL string, s *session, checkers ...policy.Checker) *mcp.CallToolResult {
+	t.Helper()
+	cfg := &config.APIConfig{BaseURL: baseURL, AllowedBaseURLs: []string{baseURL}, AllowPrivateNetworks: true}
+	tool := CreatePostusersloginTool(cfg)
+	srv := server.NewMCPServer("test", "0", server.WithToolCapabilities(false), server.WithToolHandlerMiddleware(policy.WithCheckers(checkers...)))
+	srv.AddTool(tool.Definition, tool.Handler)
+
+	ctx := context.Background()
+	if s != nil {
+		ctx = srv.WithContext(ctx, s)
+	}
+	response := srv.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "post_v2_users_login", "arguments": {"username": "alice", "password": "secret"}}}`))
+	result, ok := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+	if !ok {
+		t.Fatalf("response = %#v", response)
+	}
+	return &result
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, c := range result.Content {
+		if text, ok := c.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func TestLoginElicitsTwoFactorCode(t *testing.T) {
+	tests := []struct {
+		name          string
+		session       *session
+		checkers      []policy.Checker
+		wantError     bool
+		wantText      string // Contained in the result
+		wantAsked     int
+		wantTwoFactor int32
+		wantFallback  bool // The result carries login_2fa_token for a second call
+	}{
+		{
+			name:          "accept",
+			session:       elicitingSession(mcp.ElicitationResponseActionAccept, map[string]any{"code": "123456"}),
+			wantText:      vault.HandlePrefix,
+			wantAsked:     1,
+			wantTwoFactor: 1,
+		},
+		{
+			name:          "wrong code",
+			session:       elicitingSession(mcp.ElicitationResponseActionAccept, map[string]any{"code": "000000"}),
+			wantError:     true,
+			wantText:      "Incorrect authentication credentials",
+			wantAsked:     1,
+			wantTwoFactor: 1,
+		},
+		{
+			name:      "decline",
+			session:   elicitingSession(mcp.ElicitationResponseActionDecline, nil),
+			wantError: true,
+			wantText:  "cancelled by the user",
+			wantAsked: 1,
+		},
+		{
+			name:      "cancel",
+			session:   elicitingSession(mcp.ElicitationResponseActionCancel, nil),
+			wantError: true,
+			wantText:  "cancelled by the user",
+			wantAsked: 1,
+		},
+		{
+			name:         "accept without a code",
+			session:      elicitingSession(mcp.ElicitationResponseActionAccept, map[string]any{}),
+			wantError:    true,
+			wantText:     "no code was entered",
+			wantAsked:    1,
+			wantFallback: true,
+		},
+		{
+			name:         "elicitation fails",
+			session:      &session{capabilities: mcp.ClientCapabilities{Elicitation: &struct{}{}}, err: errors.New("stream closed")},
+			wantError:    true,
+			wantText:     "Asking for the two-factor code failed (stream closed)",
+			wantAsked:    1,
+			wantFallback: true,
+		},
+		{
+			name:         "client cannot elicit",
+			session:      &session{},
+			wantError:    true,
+			wantFallback: true,
+		},
+		{
+			name:         "no session",
+			wantError:    true,
+			wantFallback: true,
+		},
+		{
+			name:      "second step not permitted",
+			session:   elicitingSession(mcp.ElicitationResponseActionAccept, map[string]any{"code": "123456"}),
+			checkers:  []policy.Checker{denyTool("post_v2_users_2fa-login")},
+			wantError: true,
+			wantText:  `Access denied: tool post_v2_users_2fa-login is not enabled`,
+			wantAsked: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub, twoFactorCalls := hub(t)
+			result := login(t, hub.URL, tt.session, tt.checkers...)
+			text := resultText(result)
+
+			if result.IsError != tt.wantError {
+				t.Errorf("IsError = %v, want %v: %s", result.IsError, tt.wantError, text)
+			}
+			if !strings.Contains(text, tt.wantText) {
+				t.Errorf("result does not contain %q: %s", tt.wantText, text)
+			}
+			if strings.Contains(text, "jwt-secret") {
+				t.Errorf("result contains the plaintext token: %s", text)
+			}
+			if fallback := strings.Contains(text, "2fa-token"); fallback != tt.wantFallback {
+				t.Errorf("login_2fa_token returned = %v, want %v: %s", fallback, tt.wantFallback, text)
+			}
+			if tt.session != nil && tt.session.asked != tt.wantAsked {
+				t.Errorf("elicitation requests = %d, want %d", tt.session.asked, tt.wantAsked)
+			}
+			if n := twoFactorCalls.Load(); n != tt.wantTwoFactor {
+				t.Errorf("2fa-login calls = %d, want %d", n, tt.wantTwoFactor)
+			}
+		})
+	}
+}
+
+func TestCanElicit(t *testing.T) {
+	srv := server.NewMCPServer("test", "0")
+	// HandleMessage puts the server in the context of the handlers it runs
+	var withServer context.Context
+	srv.AddTool(mcp.NewTool("capture"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		withServer = ctx
+		return mcp.NewToolResultText(""), nil
+	})
+	srv.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "capture"}}`))
+	if withServer == nil {
+		t.Fatal("capture was not called")
+	}
+
+	capable := &session{capabilities: mcp.ClientCapabilities{Elicitation: &struct{}{}}}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want bool
+	}{
+		{name: "capable session", ctx: srv.WithContext(withServer, capable), want: true},
+		{name: "session without the capability", ctx: srv.WithContext(withServer, &session{}), want: false},
+		{name: "no server in context", ctx: srv.WithContext(context.Background(), capable), want: false},
+		{name: "no session", ctx: withServer, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canElicit(tt.ctx); got != tt.want {
+				t.Errorf("canElicit = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// elicitTOTPCode does not ask clients that cannot answer
+	s := &session{}
+	if _, _, err := elicitTOTPCode(srv.WithContext(withServer, s), "alice"); !errors.Is(err, errElicitationUnavailable) || s.asked != 0 {
+		t.Errorf("err = %v after %d requests, want errElicitationUnavailable", err, s.asked)
+	}
+}
@@ -8,13 +8,17 @@ import (
 	"net/http"
 	"bytes"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
+	"github.com/docker-hub-api/mcp-server/vault"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Postusers2faloginHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
 			return mcp.NewToolResultError("Invalid arguments object"), nil
@@ -31,52 +35,81 @@ func Postusers2faloginHandler(cfg *config.APIConfig) func(ctx context.Context, r
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal arguments: %v", err)), nil
 		}
 		
-		bodyBytes, err := json.Marshal(requestBody)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to encode request body", err), nil
-		}
-		url := fmt.Sprintf("%s/v2/users/2fa-login", cfg.BaseURL)
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
-		}
-		// No authentication required for this endpoint
-		req.Header.Set("Accept", "application/json")
+		return twoFactorLogin(ctx, apiCfg, requestBody), nil
+	}
+}
 
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
-		}
-		defer resp.Body.Close()
+// twoFactorLogin exchanges the code and intermediate token of requestBody for
+// a login token. post_v2_users_login uses it to finish a login whose code it
+// asked the user for.
+func twoFactorLogin(ctx context.Context, apiCfg *config.APIConfig, requestBody models.Users2FALoginRequest) *mcp.CallToolResult {
+	bodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to encode request body", err)
+	}
+	url := fmt.Sprintf("%s/v2/users/2fa-login", apiCfg.BaseURL)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to create request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
+	resp, err := upstream.Client(apiCfg).Do(req)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Request failed", err)
+	}
+	defer resp.Body.Close()
 
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result models.PostUsersLoginSuccessResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to read response body", err)
+	}
 
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
+	if resp.StatusCode >= 400 {
+		return apierror.Result(resp, body)
+	}
+	// Use properly typed response
+	var result models.PostUsersLoginSuccessResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		// The raw body would contain the secret, so it is not returned
+		return mcp.NewToolResultErrorFromErr("Failed to parse API response", err)
+	}
+	// The username is known when post_v2_users_login returned the 2FA token
+	username := upstream.Sessions.TwoFactorUsername(requestBody.Login_2fa_token)
+	description := "two-factor login"
+	if username != "" {
+		description = username
+	}
+	// Opt-in: the new token acts for the rest of this MCP session
+	sessionLogin := false
+	if result.Token != "" && apiCfg.SessionLogin {
+		sessionLogin = upstream.Sessions.Login(ctx, apiCfg.BaseURL, username, result.Token)
+	}
+	// Keep the plaintext token out of the tool result; it is revealed only via reveal_secret
+	if result.Token != "" {
+		handle, err := vault.Default.Put(ctx, vault.Secret{Value: result.Token, Kind: "login_token", Description: description})
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
+			return mcp.NewToolResultErrorFromErr("Failed to store token in vault", err)
 		}
+		result.Token = handle
+	}
+
+	prettyJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to format JSON", err)
+	}
 
-		return mcp.NewToolResultText(string(prettyJSON)), nil
+	toolResult := mcp.NewToolResultText(string(prettyJSON))
+	if sessionLogin {
+		toolResult.Content = append(toolResult.Content, mcp.NewTextContent("Later tool calls in this session use this login; call logout to stop."))
 	}
+	return toolResult
 }
 
 func CreatePostusers2faloginTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("post_v2_users_2fa-login",
-		mcp.WithDescription("Second factor authentication."),
+		mcp.WithDescription("Second factor authentication. The token in the result is a vault handle; the plaintext stays on the server and is only available through reveal_secret when enabled."),
 		mcp.WithString("code", mcp.Required(), mcp.Description("Input parameter: The Time-based One-Time Password of the Docker Hub account to authenticate with.")),
 		mcp.WithString("login_2fa_token", mcp.Required(), mcp.Description("Input parameter: The intermediate 2FA token returned from `/v2/users/login` API.")),
 	)
@@ -84,5 +117,6 @@ func CreatePostusers2faloginTool(cfg *config.APIConfig) models.Tool {
 	return models.Tool{
 		Definition: tool,
 		Handler:    Postusers2faloginHandler(cfg),
+		Group:      "authentication",
 	}
 }
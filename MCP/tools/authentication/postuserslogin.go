@@ -3,18 +3,24 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"bytes"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/policy"
+	"github.com/docker-hub-api/mcp-server/upstream"
+	"github.com/docker-hub-api/mcp-server/vault"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func PostusersloginHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
 			return mcp.NewToolResultError("Invalid arguments object"), nil
@@ -35,16 +41,15 @@ func PostusersloginHandler(cfg *config.APIConfig) func(ctx context.Context, requ
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to encode request body", err), nil
 		}
-		url := fmt.Sprintf("%s/v2/users/login", cfg.BaseURL)
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
+		url := fmt.Sprintf("%s/v2/users/login", apiCfg.BaseURL)
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(bodyBytes))
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
+		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -56,13 +61,47 @@ func PostusersloginHandler(cfg *config.APIConfig) func(ctx context.Context, requ
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			// With 2FA enabled, ask the user for the code and finish the login in this call
+			var loginErr models.PostUsersLoginErrorResponse
+			if json.Unmarshal(body, &loginErr) == nil && loginErr.Login_2fa_token != "" {
+				upstream.Sessions.ExpectTwoFactor(loginErr.Login_2fa_token, requestBody.Username)
+				code, ok, err := elicitTOTPCode(ctx, requestBody.Username)
+				switch {
+				case ok:
+					// The caller must also be allowed to make the second step as a call of its own
+					twoFactor := models.Users2FALoginRequest{Code: code, Login_2fa_token: loginErr.Login_2fa_token}
+					if err := policy.Permit(ctx, "post_v2_users_2fa-login", map[string]any{"code": twoFactor.Code, "login_2fa_token": twoFactor.Login_2fa_token}); err != nil {
+						return mcp.NewToolResultError(fmt.Sprintf("Access denied: %v", err)), nil
+					}
+					return twoFactorLogin(ctx, apiCfg, twoFactor), nil
+				case err == nil:
+					return mcp.NewToolResultError("Two-factor login cancelled by the user"), nil
+				case !errors.Is(err, errElicitationUnavailable):
+					apiErr := apierror.Parse(resp, body)
+					apiErr.Hint = fmt.Sprintf("Asking for the two-factor code failed (%v); call post_v2_users_2fa-login with the code and login_2fa_token", err)
+					return apiErr.Result(), nil
+				}
+			}
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result models.PostUsersLoginSuccessResponse
 		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
+			// The raw body would contain the secret, so it is not returned
+			return mcp.NewToolResultErrorFromErr("Failed to parse API response", err), nil
+		}
+		// Opt-in: the new token acts for the rest of this MCP session
+		sessionLogin := false
+		if result.Token != "" && apiCfg.SessionLogin {
+			sessionLogin = upstream.Sessions.Login(ctx, apiCfg.BaseURL, requestBody.Username, result.Token)
+		}
+		// Keep the plaintext token out of the tool result; it is revealed only via reveal_secret
+		if result.Token != "" {
+			handle, err := vault.Default.Put(ctx, vault.Secret{Value: result.Token, Kind: "login_token", Description: requestBody.Username})
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("Failed to store token in vault", err), nil
+			}
+			result.Token = handle
 		}
 
 		prettyJSON, err := json.MarshalIndent(result, "", "  ")
@@ -70,13 +109,17 @@ func PostusersloginHandler(cfg *config.APIConfig) func(ctx context.Context, requ
 			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
 		}
 
-		return mcp.NewToolResultText(string(prettyJSON)), nil
+		toolResult := mcp.NewToolResultText(string(prettyJSON))
+		if sessionLogin {
+			toolResult.Content = append(toolResult.Content, mcp.NewTextContent("Later tool calls in this session use this login; call logout to stop."))
+		}
+		return toolResult, nil
 	}
 }
 
 func CreatePostusersloginTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("post_v2_users_login",
-		mcp.WithDescription("Create an authentication token"),
+		mcp.WithDescription("Create an authentication token. If the account has two-factor authentication and the client supports elicitation, the user is asked for the code and the login completes in this call. The token in the result is a vault handle; the plaintext stays on the server and is only available through reveal_secret when enabled."),
 		mcp.WithString("password", mcp.Required(), mcp.Description("Input parameter: The password or personal access token (PAT) of the Docker Hub account to authenticate with.")),
 		mcp.WithString("username", mcp.Required(), mcp.Description("Input parameter: The username of the Docker Hub account to authenticate with.")),
 	)
@@ -84,5 +127,6 @@ func CreatePostusersloginTool(cfg *config.APIConfig) models.Tool {
 	return models.Tool{
 		Definition: tool,
 		Handler:    PostusersloginHandler(cfg),
+		Group:      "authentication",
 	}
 }
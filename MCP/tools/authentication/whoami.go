@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/docker-hub-api/mcp-server/auth"
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// identity describes who tool calls in a session act as.
+type identity struct {
+	BaseURL      string                 `json:"base_url"`
+	Profile      string                 `json:"profile,omitempty"`
+	Credential   string                 `json:"credential"` // session_login, bearer, basic, api_key, docker or none
+	SessionLogin *upstream.SessionLogin `json:"session_login,omitempty"`
+	Client       string                 `json:"client,omitempty"` // Authenticated MCP client, if inbound auth is enabled
+}
+
+func WhoamiHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
+		who := identity{BaseURL: apiCfg.BaseURL, Profile: apiCfg.Profile, Credential: "none"}
+		switch {
+		case apiCfg.BearerToken != "":
+			who.Credential = "bearer"
+		case apiCfg.BasicAuth != "":
+			who.Credential = "basic"
+		case apiCfg.DockerConfig != "":
+			who.Credential = "docker"
+		case apiCfg.APIKey != "":
+			who.Credential = "api_key"
+		}
+		if login, ok := upstream.Sessions.SessionLogin(ctx); ok && login.BaseURL == apiCfg.BaseURL {
+			who.Credential = "session_login"
+			who.SessionLogin = &login
+		}
+		if p, ok := auth.PrincipalFromContext(ctx); ok {
+			who.Client = p.ID()
+		}
+
+		prettyJSON, err := json.MarshalIndent(who, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
+		}
+		return mcp.NewToolResultText(string(prettyJSON)), nil
+	}
+}
+
+func CreateWhoamiTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("whoami",
+		mcp.WithDescription("Show which Docker Hub identity tool calls in this session act as"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    WhoamiHandler(cfg),
+		Group:      "authentication",
+	}
+}
+
+func LogoutHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		login, ok := upstream.Sessions.Logout(ctx)
+		if !ok {
+			return mcp.NewToolResultText("This session has no login; tool calls use the configured credentials."), nil
+		}
+		who := login.Username
+		if who == "" {
+			who = "the two-factor login"
+		}
+		return mcp.NewToolResultText("Logged out " + who + "; tool calls use the configured credentials again."), nil
+	}
+}
+
+func CreateLogoutTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("logout",
+		mcp.WithDescription("Stop using the login made in this session as its credential"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    LogoutHandler(cfg),
+		Group:      "authentication",
+	}
+}
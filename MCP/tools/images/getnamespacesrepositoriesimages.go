@@ -6,15 +6,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func GetnamespacesrepositoriesimagesHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
 			return mcp.NewToolResultError("Invalid arguments object"), nil
@@ -58,15 +62,14 @@ func GetnamespacesrepositoriesimagesHandler(cfg *config.APIConfig) func(ctx cont
 		if len(queryParams) > 0 {
 			queryString = "?" + strings.Join(queryParams, "&")
 		}
-		url := fmt.Sprintf("%s/v2/namespaces/%s/repositories/%s/images%s", cfg.BaseURL, namespace, repository, queryString)
-		req, err := http.NewRequest("GET", url, nil)
+		url := fmt.Sprintf("%s/v2/namespaces/%s/repositories/%s/images%s", apiCfg.BaseURL, url.PathEscape(namespace), url.PathEscape(repository), queryString)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -78,7 +81,7 @@ func GetnamespacesrepositoriesimagesHandler(cfg *config.APIConfig) func(ctx cont
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result models.GetNamespaceRepositoryImagesResponse
@@ -86,6 +89,9 @@ func GetnamespacesrepositoriesimagesHandler(cfg *config.APIConfig) func(ctx cont
 			// Fallback to raw text if unmarshaling fails
 			return mcp.NewToolResultText(string(body)), nil
 		}
+		// Pagination links must not lead away from the configured API origin
+		result.Next = upstream.SameOriginLink(apiCfg.BaseURL, result.Next)
+		result.Previous = upstream.SameOriginLink(apiCfg.BaseURL, result.Previous)
 
 		prettyJSON, err := json.MarshalIndent(result, "", "  ")
 		if err != nil {
@@ -104,7 +110,7 @@ func CreateGetnamespacesrepositoriesimagesTool(cfg *config.APIConfig) models.Too
 		mcp.WithString("status", mcp.Description("Filters to only show images of this status.")),
 		mcp.WithBoolean("currently_tagged", mcp.Description("Filters to only show images with:\n- `true`: at least 1 current tag.\n- `false`: no current tags.\n")),
 		mcp.WithString("ordering", mcp.Description("Orders the results by this property.\n\nPrefixing with `-` sorts by descending order.\n")),
-		mcp.WithString("active_from", mcp.Description("Sets the time from which an image must have been pushed or pulled to\nbe counted as active.\n\nDefaults to 1 month before the current time.\n")),
+		mcp.WithString("active_from", mcp.Description("Sets the time from which an image must have been pushed or pulled to\nbe counted as active.\n\nDefaults to 1 month before the current time.\n\nAccepts RFC 3339, a date (2026-10-01), a duration before now (7d, -2h), or today/yesterday.")),
 		mcp.WithNumber("page", mcp.Description("Page number to get. Defaults to 1.")),
 		mcp.WithNumber("page_size", mcp.Description("Number of images to get per page. Defaults to 10. Max of 100.")),
 	)
@@ -112,5 +118,6 @@ func CreateGetnamespacesrepositoriesimagesTool(cfg *config.APIConfig) models.Too
 	return models.Tool{
 		Definition: tool,
 		Handler:    GetnamespacesrepositoriesimagesHandler(cfg),
+		Group:      "images",
 	}
 }
@@ -6,15 +6,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func GetnamespacesrepositoriesimagessummaryHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
 			return mcp.NewToolResultError("Invalid arguments object"), nil
@@ -43,15 +47,14 @@ func GetnamespacesrepositoriesimagessummaryHandler(cfg *config.APIConfig) func(c
 		if len(queryParams) > 0 {
 			queryString = "?" + strings.Join(queryParams, "&")
 		}
-		url := fmt.Sprintf("%s/v2/namespaces/%s/repositories/%s/images-summary%s", cfg.BaseURL, namespace, repository, queryString)
-		req, err := http.NewRequest("GET", url, nil)
+		url := fmt.Sprintf("%s/v2/namespaces/%s/repositories/%s/images-summary%s", apiCfg.BaseURL, url.PathEscape(namespace), url.PathEscape(repository), queryString)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -63,7 +66,7 @@ func GetnamespacesrepositoriesimagessummaryHandler(cfg *config.APIConfig) func(c
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result models.GetNamespaceRepositoryImagesSummaryResponse
@@ -86,11 +89,12 @@ func CreateGetnamespacesrepositoriesimagessummaryTool(cfg *config.APIConfig) mod
 		mcp.WithDescription("Get summary of repository's images"),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace of the repository.")),
 		mcp.WithString("repository", mcp.Required(), mcp.Description("Name of the repository.")),
-		mcp.WithString("active_from", mcp.Description("Sets the time from which an image must have been pushed or pulled to\nbe counted as active.\n\nDefaults to 1 month before the current time.\n")),
+		mcp.WithString("active_from", mcp.Description("Sets the time from which an image must have been pushed or pulled to\nbe counted as active.\n\nDefaults to 1 month before the current time.\n\nAccepts RFC 3339, a date (2026-10-01), a duration before now (7d, -2h), or today/yesterday.")),
 	)
 
 	return models.Tool{
 		Definition: tool,
 		Handler:    GetnamespacesrepositoriesimagessummaryHandler(cfg),
+		Group:      "images",
 	}
 }
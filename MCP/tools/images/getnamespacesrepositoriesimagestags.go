@@ -6,15 +6,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func GetnamespacesrepositoriesimagestagsHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
 			return mcp.NewToolResultError("Invalid arguments object"), nil
@@ -54,15 +58,14 @@ func GetnamespacesrepositoriesimagestagsHandler(cfg *config.APIConfig) func(ctx
 		if len(queryParams) > 0 {
 			queryString = "?" + strings.Join(queryParams, "&")
 		}
-		url := fmt.Sprintf("%s/v2/namespaces/%s/repositories/%s/images/%s/tags%s", cfg.BaseURL, namespace, repository, digest, queryString)
-		req, err := http.NewRequest("GET", url, nil)
+		url := fmt.Sprintf("%s/v2/namespaces/%s/repositories/%s/images/%s/tags%s", apiCfg.BaseURL, url.PathEscape(namespace), url.PathEscape(repository), url.PathEscape(digest), queryString)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -74,7 +77,7 @@ func GetnamespacesrepositoriesimagestagsHandler(cfg *config.APIConfig) func(ctx
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result models.GetNamespaceRepositoryImagesTagsResponse
@@ -82,6 +85,9 @@ func GetnamespacesrepositoriesimagestagsHandler(cfg *config.APIConfig) func(ctx
 			// Fallback to raw text if unmarshaling fails
 			return mcp.NewToolResultText(string(body)), nil
 		}
+		// Pagination links must not lead away from the configured API origin
+		result.Next = upstream.SameOriginLink(apiCfg.BaseURL, result.Next)
+		result.Previous = upstream.SameOriginLink(apiCfg.BaseURL, result.Previous)
 
 		prettyJSON, err := json.MarshalIndent(result, "", "  ")
 		if err != nil {
@@ -105,5 +111,6 @@ func CreateGetnamespacesrepositoriesimagestagsTool(cfg *config.APIConfig) models
 	return models.Tool{
 		Definition: tool,
 		Handler:    GetnamespacesrepositoriesimagestagsHandler(cfg),
+		Group:      "images",
 	}
 }
@@ -6,15 +6,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"bytes"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func PostnamespacesdeleteimagesHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
 			return mcp.NewToolResultError("Invalid arguments object"), nil
@@ -43,16 +47,15 @@ func PostnamespacesdeleteimagesHandler(cfg *config.APIConfig) func(ctx context.C
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to encode request body", err), nil
 		}
-		url := fmt.Sprintf("%s/v2/namespaces/%s/delete-images", cfg.BaseURL, namespace)
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyBytes))
+		url := fmt.Sprintf("%s/v2/namespaces/%s/delete-images", apiCfg.BaseURL, url.PathEscape(namespace))
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(bodyBytes))
 		req.Header.Set("Content-Type", "application/json")
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -64,7 +67,7 @@ func PostnamespacesdeleteimagesHandler(cfg *config.APIConfig) func(ctx context.C
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result models.PostNamespacesDeleteImagesResponseSuccess
@@ -86,7 +89,7 @@ func CreatePostnamespacesdeleteimagesTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("post_v2_namespaces_namespace_delete-images",
 		mcp.WithDescription("Delete images"),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace of the repository.")),
-		mcp.WithString("active_from", mcp.Description("Input parameter: Sets the time from which an image must have been pushed or pulled to\nbe counted as active.\n\nDefaults to 1 month before the current time.\n")),
+		mcp.WithString("active_from", mcp.Description("Input parameter: Sets the time from which an image must have been pushed or pulled to\nbe counted as active.\n\nDefaults to 1 month before the current time.\n\nAccepts RFC 3339, a date (2026-10-01), a duration before now (7d, -2h), or today/yesterday.")),
 		mcp.WithBoolean("dry_run", mcp.Description("Input parameter: If `true` then will check and return errors and unignored warnings for the deletion request but will not delete any images.")),
 		mcp.WithArray("ignore_warnings", mcp.Description("Input parameter: Warnings to ignore. If a warning is not ignored then no deletions will happen and the \nwarning is returned in the response.\n\nThese warnings include:\n\n- is_active: warning when attempting to delete an image that is marked as active.\n- current_tag: warning when attempting to delete an image that has one or more current \ntags in the repository.\n\nWarnings can be copied from the response to the request.\n")),
 		mcp.WithArray("manifests", mcp.Description("Input parameter: Image manifests to delete.")),
@@ -95,5 +98,6 @@ func CreatePostnamespacesdeleteimagesTool(cfg *config.APIConfig) models.Tool {
 	return models.Tool{
 		Definition: tool,
 		Handler:    PostnamespacesdeleteimagesHandler(cfg),
+		Group:      "images",
 	}
 }
@@ -6,23 +6,38 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Get_v2_orgs_name_settingsHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		url := fmt.Sprintf("%s/v2/orgs/%s/settings", cfg.BaseURL)
-		req, err := http.NewRequest("GET", url, nil)
+		apiCfg := config.FromContext(ctx, cfg)
+		args, ok := request.Params.Arguments.(map[string]any)
+		if !ok {
+			return mcp.NewToolResultError("Invalid arguments object"), nil
+		}
+		nameVal, ok := args["name"]
+		if !ok {
+			return mcp.NewToolResultError("Missing required path parameter: name"), nil
+		}
+		name, ok := nameVal.(string)
+		if !ok {
+			return mcp.NewToolResultError("Invalid path parameter: name"), nil
+		}
+		url := fmt.Sprintf("%s/v2/orgs/%s/settings", apiCfg.BaseURL, url.PathEscape(name))
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -34,7 +49,7 @@ func Get_v2_orgs_name_settingsHandler(cfg *config.APIConfig) func(ctx context.Co
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result models.OrgSettings
@@ -55,10 +70,12 @@ func Get_v2_orgs_name_settingsHandler(cfg *config.APIConfig) func(ctx context.Co
 func CreateGet_v2_orgs_name_settingsTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("get_v2_orgs_name_settings",
 		mcp.WithDescription("Get organization settings"),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the organization.")),
 	)
 
 	return models.Tool{
 		Definition: tool,
 		Handler:    Get_v2_orgs_name_settingsHandler(cfg),
+		Group:      "org_settings",
 	}
 }
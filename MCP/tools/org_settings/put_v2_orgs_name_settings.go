@@ -6,21 +6,33 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"bytes"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Put_v2_orgs_name_settingsHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
 			return mcp.NewToolResultError("Invalid arguments object"), nil
 		}
+		nameVal, ok := args["name"]
+		if !ok {
+			return mcp.NewToolResultError("Missing required path parameter: name"), nil
+		}
+		name, ok := nameVal.(string)
+		if !ok {
+			return mcp.NewToolResultError("Invalid path parameter: name"), nil
+		}
 		// Create properly typed request body using the generated schema
-		var requestBody interface{}
+		var requestBody models.OrgSettings
 		
 		// Optimized: Single marshal/unmarshal with JSON tags handling field mapping
 		if argsJSON, err := json.Marshal(args); err == nil {
@@ -35,16 +47,15 @@ func Put_v2_orgs_name_settingsHandler(cfg *config.APIConfig) func(ctx context.Co
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to encode request body", err), nil
 		}
-		url := fmt.Sprintf("%s/v2/orgs/%s/settings", cfg.BaseURL)
-		req, err := http.NewRequest("PUT", url, bytes.NewBuffer(bodyBytes))
+		url := fmt.Sprintf("%s/v2/orgs/%s/settings", apiCfg.BaseURL, url.PathEscape(name))
+		req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(bodyBytes))
 		req.Header.Set("Content-Type", "application/json")
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -56,7 +67,7 @@ func Put_v2_orgs_name_settingsHandler(cfg *config.APIConfig) func(ctx context.Co
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result models.OrgSettings
@@ -77,11 +88,13 @@ func Put_v2_orgs_name_settingsHandler(cfg *config.APIConfig) func(ctx context.Co
 func CreatePut_v2_orgs_name_settingsTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("put_v2_orgs_name_settings",
 		mcp.WithDescription("Update organization settings"),
-		mcp.WithString("restricted_images", mcp.Required(), mcp.Description("")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the organization.")),
+		mcp.WithObject("restricted_images", mcp.Required(), mcp.Description("Input parameter: Restricted images settings of the organization.")),
 	)
 
 	return models.Tool{
 		Definition: tool,
 		Handler:    Put_v2_orgs_name_settingsHandler(cfg),
+		Group:      "org_settings",
 	}
 }
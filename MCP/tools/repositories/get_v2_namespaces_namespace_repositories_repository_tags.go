@@ -6,19 +6,39 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
+	"github.com/docker-hub-api/mcp-server/apierror"
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Get_v2_namespaces_namespace_repositories_repository_tagsHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		apiCfg := config.FromContext(ctx, cfg)
 		args, ok := request.Params.Arguments.(map[string]any)
 		if !ok {
 			return mcp.NewToolResultError("Invalid arguments object"), nil
 		}
+		namespaceVal, ok := args["namespace"]
+		if !ok {
+			return mcp.NewToolResultError("Missing required path parameter: namespace"), nil
+		}
+		namespace, ok := namespaceVal.(string)
+		if !ok {
+			return mcp.NewToolResultError("Invalid path parameter: namespace"), nil
+		}
+		repositoryVal, ok := args["repository"]
+		if !ok {
+			return mcp.NewToolResultError("Missing required path parameter: repository"), nil
+		}
+		repository, ok := repositoryVal.(string)
+		if !ok {
+			return mcp.NewToolResultError("Invalid path parameter: repository"), nil
+		}
 		queryParams := make([]string, 0)
 		if val, ok := args["page"]; ok {
 			queryParams = append(queryParams, fmt.Sprintf("page=%v", val))
@@ -30,15 +50,14 @@ func Get_v2_namespaces_namespace_repositories_repository_tagsHandler(cfg *config
 		if len(queryParams) > 0 {
 			queryString = "?" + strings.Join(queryParams, "&")
 		}
-		url := fmt.Sprintf("%s/v2/namespaces/%s/repositories/%s/tags%s", cfg.BaseURL, queryString)
-		req, err := http.NewRequest("GET", url, nil)
+		url := fmt.Sprintf("%s/v2/namespaces/%s/repositories/%s/tags%s", apiCfg.BaseURL, url.PathEscape(namespace), url.PathEscape(repository), queryString)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
@@ -50,7 +69,7 @@ func Get_v2_namespaces_namespace_repositories_repository_tagsHandler(cfg *config
 		}
 
 		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
+			return apierror.Result(resp, body), nil
 		}
 		// Use properly typed response
 		var result models.Paginatedtags
@@ -58,6 +77,9 @@ func Get_v2_namespaces_namespace_repositories_repository_tagsHandler(cfg *config
 			// Fallback to raw text if unmarshaling fails
 			return mcp.NewToolResultText(string(body)), nil
 		}
+		// Pagination links must not lead away from the configured API origin
+		result.Next = upstream.SameOriginLink(apiCfg.BaseURL, result.Next)
+		result.Previous = upstream.SameOriginLink(apiCfg.BaseURL, result.Previous)
 
 		prettyJSON, err := json.MarshalIndent(result, "", "  ")
 		if err != nil {
@@ -71,6 +93,8 @@ func Get_v2_namespaces_namespace_repositories_repository_tagsHandler(cfg *config
 func CreateGet_v2_namespaces_namespace_repositories_repository_tagsTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("get_v2_namespaces_namespace_repositories_repository_tags",
 		mcp.WithDescription("List repository tags"),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace of the repository.")),
+		mcp.WithString("repository", mcp.Required(), mcp.Description("Name of the repository.")),
 		mcp.WithNumber("page", mcp.Description("Page number to get. Defaults to 1.")),
 		mcp.WithNumber("page_size", mcp.Description("Number of items to get per page. Defaults to 10. Max of 100.")),
 	)
@@ -78,5 +102,6 @@ func CreateGet_v2_namespaces_namespace_repositories_repository_tagsTool(cfg *con
 	return models.Tool{
 		Definition: tool,
 		Handler:    Get_v2_namespaces_namespace_repositories_repository_tagsHandler(cfg),
+		Group:      "repositories",
 	}
 }
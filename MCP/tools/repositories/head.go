@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/docker-hub-api/mcp-server/apierror"
+	"github.com/docker-hub-api/mcp-server/upstream"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// headResult is the result of the HEAD tools.
+type headResult struct {
+	Exists       bool                `json:"exists"`
+	Status       int                 `json:"status"`
+	RateLimit    *upstream.RateLimit `json:"rate_limit,omitempty"`
+	ETag         string              `json:"etag,omitempty"`
+	LastModified string              `json:"last_modified,omitempty"`
+}
+
+// headToolResult describes the response to a HEAD request. A 404 is reported
+// as exists=false; other error statuses are API errors.
+func headToolResult(resp *http.Response) *mcp.CallToolResult {
+	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound {
+		return apierror.Result(resp, nil)
+	}
+	result := headResult{
+		Exists:       resp.StatusCode < 300,
+		Status:       resp.StatusCode,
+		RateLimit:    upstream.RateLimitFromHeader(resp.Header),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	prettyJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to format JSON", err)
+	}
+	return mcp.NewToolResultStructured(result, string(prettyJSON))
+}
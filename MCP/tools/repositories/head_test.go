@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/apierror"
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/upstream"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHeadToolResult(t *testing.T) {
+	reset := time.Unix(1767268800, 0).UTC()
+	tests := []struct {
+		name    string
+		status  int
+		header  map[string]string
+		want    *headResult // nil when the result is an API error
+		wantErr int         // Status of the API error
+	}{
+		{
+			name:   "exists",
+			status: http.StatusOK,
+			header: map[string]string{
+				"ETag": `"abc"`, "Last-Modified": "Thu, 01 Jan 2026 12:00:00 GMT",
+				"X-RateLimit-Limit": "180", "X-RateLimit-Remaining": "179", "X-RateLimit-Reset": "1767268800",
+			},
+			want: &headResult{
+				Exists: true, Status: http.StatusOK, ETag: `"abc"`, LastModified: "Thu, 01 Jan 2026 12:00:00 GMT",
+				RateLimit: &upstream.RateLimit{Limit: 180, Remaining: 179, Reset: &reset},
+			},
+		},
+		{
+			name:   "registry rate limit headers",
+			status: http.StatusOK,
+			header: map[string]string{"RateLimit-Limit": "100;w=21600", "RateLimit-Remaining": "76;w=21600"},
+			want:   &headResult{Exists: true, Status: http.StatusOK, RateLimit: &upstream.RateLimit{Limit: 100, Remaining: 76}},
+		},
+		{
+			name:   "no optional headers",
+			status: http.StatusOK,
+			want:   &headResult{Exists: true, Status: http.StatusOK},
+		},
+		{
+			name:   "not found",
+			status: http.StatusNotFound,
+			header: map[string]string{"X-RateLimit-Limit": "180", "X-RateLimit-Remaining": "10"},
+			want:   &headResult{Exists: false, Status: http.StatusNotFound, RateLimit: &upstream.RateLimit{Limit: 180, Remaining: 10}},
+		},
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: http.StatusUnauthorized},
+		{name: "rate limited", status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "60"}, wantErr: http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, path string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method, path = r.Method, r.URL.EscapedPath()
+				for name, value := range tt.header {
+					w.Header().Set(name, value)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			cfg := &config.APIConfig{BaseURL: srv.URL, AllowedBaseURLs: []string{srv.URL}, AllowPrivateNetworks: true}
+			handler := Head_v2_namespaces_namespace_repositories_repository_tags_tagHandler(cfg)
+			var request mcp.CallToolRequest
+			request.Params.Arguments = map[string]any{"namespace": "acme", "repository": "web", "tag": "v1/rc"}
+			result, err := handler(context.Background(), request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if method != http.MethodHead || path != "/v2/namespaces/acme/repositories/web/tags/v1%2Frc" {
+				t.Errorf("request = %s %s", method, path)
+			}
+
+			if tt.want == nil {
+				e, ok := result.StructuredContent.(*apierror.Error)
+				if !result.IsError || !ok || e.Status != tt.wantErr {
+					t.Fatalf("result = %+v, want API error %d", result, tt.wantErr)
+				}
+				return
+			}
+			got, ok := result.StructuredContent.(headResult)
+			if result.IsError || !ok {
+				t.Fatalf("result = %+v", result)
+			}
+			if !reflect.DeepEqual(got, *tt.want) {
+				t.Errorf("got %+v, want %+v", got, *tt.want)
+			}
+		})
+	}
+}
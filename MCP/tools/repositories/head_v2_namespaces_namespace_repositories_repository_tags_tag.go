@@ -2,63 +2,76 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 
 	"github.com/docker-hub-api/mcp-server/config"
 	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/upstream"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func Head_v2_namespaces_namespace_repositories_repository_tags_tagHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		url := fmt.Sprintf("%s/v2/namespaces/%s/repositories/%s/tags/%s", cfg.BaseURL)
-		req, err := http.NewRequest("HEAD", url, nil)
+		apiCfg := config.FromContext(ctx, cfg)
+		args, ok := request.Params.Arguments.(map[string]any)
+		if !ok {
+			return mcp.NewToolResultError("Invalid arguments object"), nil
+		}
+		namespaceVal, ok := args["namespace"]
+		if !ok {
+			return mcp.NewToolResultError("Missing required path parameter: namespace"), nil
+		}
+		namespace, ok := namespaceVal.(string)
+		if !ok {
+			return mcp.NewToolResultError("Invalid path parameter: namespace"), nil
+		}
+		repositoryVal, ok := args["repository"]
+		if !ok {
+			return mcp.NewToolResultError("Missing required path parameter: repository"), nil
+		}
+		repository, ok := repositoryVal.(string)
+		if !ok {
+			return mcp.NewToolResultError("Invalid path parameter: repository"), nil
+		}
+		tagVal, ok := args["tag"]
+		if !ok {
+			return mcp.NewToolResultError("Missing required path parameter: tag"), nil
+		}
+		tag, ok := tagVal.(string)
+		if !ok {
+			return mcp.NewToolResultError("Invalid path parameter: tag"), nil
+		}
+		url := fmt.Sprintf("%s/v2/namespaces/%s/repositories/%s/tags/%s", apiCfg.BaseURL, url.PathEscape(namespace), url.PathEscape(repository), url.PathEscape(tag))
+		req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Failed to create request", err), nil
 		}
-		// No authentication required for this endpoint
 		req.Header.Set("Accept", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := upstream.Client(apiCfg).Do(req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("Request failed", err), nil
 		}
 		defer resp.Body.Close()
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to read response body", err), nil
-		}
-
-		if resp.StatusCode >= 400 {
-			return mcp.NewToolResultError(fmt.Sprintf("API error: %s", body)), nil
-		}
-		// Use properly typed response
-		var result map[string]interface{}
-		if err := json.Unmarshal(body, &result); err != nil {
-			// Fallback to raw text if unmarshaling fails
-			return mcp.NewToolResultText(string(body)), nil
-		}
-
-		prettyJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("Failed to format JSON", err), nil
-		}
-
-		return mcp.NewToolResultText(string(prettyJSON)), nil
+		return headToolResult(resp), nil
 	}
 }
 
 func CreateHead_v2_namespaces_namespace_repositories_repository_tags_tagTool(cfg *config.APIConfig) models.Tool {
 	tool := mcp.NewTool("head_v2_namespaces_namespace_repositories_repository_tags_tag",
-		mcp.WithDescription("Check repository tag"),
+		mcp.WithDescription("Check repository tag. Returns whether the tag exists, without transferring the response body, plus the status, rate limit, ETag and Last-Modified headers. A 404 is reported as exists=false."),
+		mcp.WithOutputSchema[headResult](),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("Namespace of the repository.")),
+		mcp.WithString("repository", mcp.Required(), mcp.Description("Name of the repository.")),
+		mcp.WithString("tag", mcp.Required(), mcp.Description("Name of the tag.")),
 	)
 
 	return models.Tool{
 		Definition: tool,
 		Handler:    Head_v2_namespaces_namespace_repositories_repository_tags_tagHandler(cfg),
+		Group:      "repositories",
 	}
 }
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/docker-hub-api/mcp-server/vault"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func Reveal_secretHandler(cfg *config.APIConfig) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]any)
+		if !ok {
+			return mcp.NewToolResultError("Invalid arguments object"), nil
+		}
+		handle, ok := args["handle"].(string)
+		if !ok || handle == "" {
+			return mcp.NewToolResultError("Missing required parameter: handle"), nil
+		}
+		v := vault.Default
+		secret, err := v.Get(ctx, handle)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		file, _ := args["file"].(string)
+		if file == "" {
+			if !v.AllowReveal {
+				return mcp.NewToolResultError("Revealing secrets in tool results is disabled (VAULT_ALLOW_REVEAL); pass file to write it to VAULT_EXPORT_DIR instead"), nil
+			}
+			return mcp.NewToolResultText(secret.Value), nil
+		}
+
+		if v.ExportDir == "" {
+			return mcp.NewToolResultError("Writing secrets to files is disabled (VAULT_EXPORT_DIR is not set)"), nil
+		}
+		// Only plain file names inside the export directory are accepted
+		if file != filepath.Base(file) || file == "." || file == ".." {
+			return mcp.NewToolResultError("Invalid parameter: file must be a file name without directories"), nil
+		}
+		path := filepath.Join(v.ExportDir, file)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("Failed to create secret file", err), nil
+		}
+		if _, err := f.WriteString(secret.Value + "\n"); err != nil {
+			f.Close()
+			os.Remove(path)
+			return mcp.NewToolResultErrorFromErr("Failed to write secret file", err), nil
+		}
+		if err := f.Close(); err != nil {
+			return mcp.NewToolResultErrorFromErr("Failed to write secret file", err), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("%s (%s) written to %s", secret.Kind, secret.Description, path)), nil
+	}
+}
+
+func CreateReveal_secretTool(cfg *config.APIConfig) models.Tool {
+	tool := mcp.NewTool("reveal_secret",
+		mcp.WithDescription("Reveal a secret held in the server's vault, either in the result or by writing it to a local file"),
+		mcp.WithString("handle", mcp.Required(), mcp.Description("Input parameter: Vault handle returned by another tool, e.g. \"vault:...\".")),
+		mcp.WithString("file", mcp.Description("Input parameter: File name in the server's export directory to write the secret to instead of returning it.")),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	return models.Tool{
+		Definition: tool,
+		Handler:    Reveal_secretHandler(cfg),
+		Group:      "secrets",
+	}
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/vault"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRevealSecret(t *testing.T) {
+	const existing = "existing.txt"
+	tests := []struct {
+		name        string
+		allowReveal bool
+		export      bool // Whether VAULT_EXPORT_DIR is set
+		args        map[string]any
+		wantError   string // Part of the tool error
+		wantText    string
+		wantFile    string // File in the export directory holding the secret
+	}{
+		{name: "reveal disabled", args: map[string]any{}, wantError: "VAULT_ALLOW_REVEAL"},
+		{name: "reveal enabled", allowReveal: true, args: map[string]any{}, wantText: "s3cret"},
+		{name: "unknown handle", allowReveal: true, args: map[string]any{"handle": vault.HandlePrefix + "unknown"}, wantError: "unknown"},
+		{name: "no handle", allowReveal: true, args: map[string]any{"handle": ""}, wantError: "Missing required parameter: handle"},
+		{name: "export disabled", allowReveal: true, args: map[string]any{"file": "token.txt"}, wantError: "VAULT_EXPORT_DIR is not set"},
+		{name: "export", export: true, args: map[string]any{"file": "token.txt"}, wantText: "written to", wantFile: "token.txt"},
+		{name: "parent directory", export: true, args: map[string]any{"file": "../token.txt"}, wantError: "without directories"},
+		{name: "subdirectory", export: true, args: map[string]any{"file": "sub/token.txt"}, wantError: "without directories"},
+		{name: "absolute path", export: true, args: map[string]any{"file": "/tmp/token.txt"}, wantError: "without directories"},
+		{name: "dot", export: true, args: map[string]any{"file": "."}, wantError: "without directories"},
+		{name: "dot dot", export: true, args: map[string]any{"file": ".."}, wantError: "without directories"},
+		{name: "existing file", export: true, args: map[string]any{"file": existing}, wantError: "file exists"},
+		{name: "symlink", export: true, args: map[string]any{"file": "link.txt"}, wantError: "file exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := vault.Default
+			defer func() { vault.Default = saved }()
+			v := vault.New()
+			v.AllowReveal = tt.allowReveal
+			outside := t.TempDir()
+			if tt.export {
+				v.ExportDir = t.TempDir()
+				if err := os.WriteFile(filepath.Join(v.ExportDir, existing), []byte("keep\n"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Symlink(filepath.Join(outside, "target.txt"), filepath.Join(v.ExportDir, "link.txt")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			vault.Default = v
+
+			ctx := context.Background()
+			handle, err := v.Put(ctx, vault.Secret{Value: "s3cret", Kind: "access_token", Description: "ci"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			args := map[string]any{"handle": handle}
+			for name, value := range tt.args {
+				args[name] = value
+			}
+			var request mcp.CallToolRequest
+			request.Params.Arguments = args
+			result, err := Reveal_secretHandler(&config.APIConfig{})(ctx, request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+
+			if tt.wantError != "" {
+				if !result.IsError || !strings.Contains(text, tt.wantError) {
+					t.Fatalf("result = %q, want error %q", text, tt.wantError)
+				}
+			} else if result.IsError || !strings.Contains(text, tt.wantText) {
+				t.Fatalf("result = %q, want %q", text, tt.wantText)
+			}
+			if tt.wantError != "" && strings.Contains(text, "s3cret") {
+				t.Errorf("error reveals the secret: %q", text)
+			}
+
+			if tt.wantFile != "" {
+				path := filepath.Join(v.ExportDir, tt.wantFile)
+				data, err := os.ReadFile(path)
+				if err != nil || string(data) != "s3cret\n" {
+					t.Errorf("file = %q, %v", data, err)
+				}
+				if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+					t.Errorf("file mode = %v, %v", info.Mode(), err)
+				}
+			}
+			if tt.export {
+				if data, _ := os.ReadFile(filepath.Join(v.ExportDir, existing)); string(data) != "keep\n" {
+					t.Errorf("existing file overwritten: %q", data)
+				}
+				if _, err := os.Stat(filepath.Join(outside, "target.txt")); err == nil {
+					t.Error("secret written through a symlink")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package upstream
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/docker-hub-api/mcp-server/cache"
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/dockercreds"
+)
+
+// Client returns the HTTP client tool handlers use to call the API described by
+// cfg. Requests are only sent to origins in cfg.AllowedBaseURLs, redirects must
+// stay on the same origin, connections use cfg.Upstream's TLS and proxy
+// settings, and GET responses of tool calls may be served from the response
+// cache.
+//
+// The credentials of cfg are attached to each request, so handlers only set
+// the request's own headers.
+func Client(cfg *config.APIConfig) *http.Client {
+	return &http.Client{
+		Transport:     &authTransport{cfg: cfg},
+		CheckRedirect: sameOriginRedirect,
+	}
+}
+
+type authTransport struct {
+	cfg *config.APIConfig
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := CheckBaseURL(t.cfg, req.URL.String()); err != nil {
+		return nil, err
+	}
+	base, err := Transport(t.cfg)
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	var sessionToken string
+	if req.Header.Get("Authorization") == "" {
+		login, loggedIn := Sessions.SessionLogin(req.Context())
+		switch {
+		case loggedIn && login.BaseURL == t.cfg.BaseURL:
+			// A login made in this MCP session acts for the session
+			req.Header.Set("Authorization", "Bearer "+login.token)
+		case t.cfg.BearerToken != "":
+			req.Header.Set("Authorization", "Bearer "+t.cfg.BearerToken)
+		case t.cfg.BasicAuth != "":
+			req.Header.Set("Authorization", "Basic "+basicCredentials(t.cfg.BasicAuth))
+		case t.cfg.DockerConfig != "":
+			if sessionToken, err = dockerSessionToken(req.Context(), t.cfg); err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "Bearer "+sessionToken)
+		}
+	}
+	if t.cfg.APIKey != "" {
+		req.Header.Set("X-API-Key", t.cfg.APIKey)
+	}
+	// Credentials are attached first, so cached responses are kept per identity
+	resp, err := cache.Default.RoundTrip(req, base)
+	if err == nil && sessionToken != "" && resp.StatusCode == http.StatusUnauthorized {
+		// Log in again on the next request
+		Sessions.Invalidate(sessionToken)
+	}
+	return resp, err
+}
+
+// DockerStore returns the credential store for a Docker config file. It can be
+// replaced to inject a different credential helper runner.
+var DockerStore = func(path string) *dockercreds.Store {
+	return &dockercreds.Store{Path: path}
+}
+
+// dockerSessionToken logs in with the credentials "docker login" saved for
+// cfg.BaseURL's registry.
+func dockerSessionToken(ctx context.Context, cfg *config.APIConfig) (string, error) {
+	return Sessions.Token(ctx, cfg, "docker:"+cfg.DockerConfig, func(ctx context.Context) (string, string, error) {
+		cred, err := DockerStore(cfg.DockerConfig).Get(ctx, dockercreds.RegistryForBaseURL(cfg.BaseURL))
+		if err != nil {
+			return "", "", err
+		}
+		return cred.Username, cred.Secret, nil
+	})
+}
+
+// basicCredentials accepts either "user:password" or its base64 encoding.
+func basicCredentials(value string) string {
+	if strings.Contains(value, ":") {
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	return value
+}
+
+func sameOriginRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+	if origin(req.URL) != origin(via[0].URL) {
+		return fmt.Errorf("refusing cross-origin redirect to %s", origin(req.URL))
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+// cgnat is the carrier-grade NAT range, not covered by netip's IsPrivate.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// CheckBaseURL returns an error unless rawURL is http(s) and lies under one of
+// cfg.AllowedBaseURLs (same scheme, host, port and path prefix).
+func CheckBaseURL(cfg *config.APIConfig, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid API base URL %q", rawURL)
+	}
+	for _, allowed := range cfg.AllowedBaseURLs {
+		a, err := url.Parse(allowed)
+		if err != nil || origin(a) != origin(u) {
+			continue
+		}
+		prefix := strings.TrimSuffix(a.Path, "/")
+		if u.Path == prefix || strings.HasPrefix(u.Path, prefix+"/") || prefix == "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("API base URL %s is not in ALLOWED_BASE_URLS", origin(u))
+}
+
+// SameOriginLink returns link when it points at the same origin as baseURL and
+// "" otherwise, so pagination links cannot steer callers to another host.
+func SameOriginLink(baseURL, link string) string {
+	if link == "" {
+		return ""
+	}
+	b, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	l, err := url.Parse(link)
+	if err != nil || origin(l) != origin(b) {
+		return ""
+	}
+	return link
+}
+
+// origin returns scheme://host:port with default ports made explicit.
+func origin(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if strings.EqualFold(u.Scheme, "https") {
+			port = "443"
+		}
+	}
+	return strings.ToLower(u.Scheme) + "://" + net.JoinHostPort(strings.ToLower(u.Hostname()), port)
+}
+
+// guardedDialer resolves the target host once, rejects it if any address is
+// not public (unless allowPrivate), and dials the checked addresses directly so
+// the connection is pinned to what was validated. Addresses in trusted, such as
+// an operator-configured proxy, are dialed without the check.
+func guardedDialer(dialer *net.Dialer, allowPrivate bool, trusted map[string]bool) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if trusted[addr] {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := resolveChecked(ctx, host, allowPrivate)
+		if err != nil {
+			return nil, err
+		}
+		var lastErr error
+		for _, ip := range ips {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.Unmap().String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no addresses found for %s", host)
+		}
+		return nil, lastErr
+	}
+}
+
+// resolveChecked resolves host and, unless allowPrivate, fails if any address is not public.
+func resolveChecked(ctx context.Context, host string, allowPrivate bool) ([]netip.Addr, error) {
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	if !allowPrivate {
+		for _, ip := range ips {
+			if !isPublic(ip) {
+				return nil, fmt.Errorf("upstream host %s resolves to non-public address %s (set ALLOW_PRIVATE_NETWORKS=true to permit)", host, ip.Unmap())
+			}
+		}
+	}
+	return ips, nil
+}
+
+func isPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !cgnat.Contains(ip)
+}
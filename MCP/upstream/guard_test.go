@@ -0,0 +1,123 @@
+package upstream
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"1.1.1.1", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false}, // Cloud metadata
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"100.64.0.1", false}, // Carrier-grade NAT
+		{"0.0.0.0", false},
+		{"::", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"::ffff:8.8.8.8", true},
+	}
+	for _, tt := range tests {
+		if got := isPublic(netip.MustParseAddr(tt.ip)); got != tt.want {
+			t.Errorf("isPublic(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestCheckBaseURL(t *testing.T) {
+	cfg := &config.APIConfig{AllowedBaseURLs: []string{"https://hub.docker.com", "https://hub.example.com:8443/api"}}
+	tests := []struct {
+		url  string
+		want string // Part of the error; empty when allowed
+	}{
+		{"https://hub.docker.com", ""},
+		{"https://hub.docker.com:443/", ""},
+		{"https://HUB.docker.com/v2", ""},
+		{"https://hub.example.com:8443/api", ""},
+		{"https://hub.example.com:8443/api/v2", ""},
+		{"https://hub.example.com:8443/apix", "not in ALLOWED_BASE_URLS"},
+		{"https://hub.example.com/api", "not in ALLOWED_BASE_URLS"},
+		{"http://hub.docker.com", "not in ALLOWED_BASE_URLS"},
+		{"https://hub.docker.com.evil.example", "not in ALLOWED_BASE_URLS"},
+		{"https://hub.docker.com@169.254.169.254", "not in ALLOWED_BASE_URLS"},
+		{"file:///etc/passwd", "invalid API base URL"},
+		{"hub.docker.com", "invalid API base URL"},
+		{"https://", "invalid API base URL"},
+	}
+	for _, tt := range tests {
+		err := CheckBaseURL(cfg, tt.url)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("CheckBaseURL(%q) = %v, want %q", tt.url, err, tt.want)
+		}
+	}
+}
+
+func TestSameOriginLink(t *testing.T) {
+	const base = "https://hub.docker.com"
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"https://hub.docker.com/v2/namespaces/acme/repositories?page=2", "https://hub.docker.com/v2/namespaces/acme/repositories?page=2"},
+		{"https://hub.docker.com:443/v2/x", "https://hub.docker.com:443/v2/x"},
+		{"http://hub.docker.com/v2/x", ""},
+		{"https://evil.example/v2/x", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := SameOriginLink(base, tt.link); got != tt.want {
+			t.Errorf("SameOriginLink(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestGuardedDialer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	_, port, _ := net.SplitHostPort(addr)
+
+	tests := []struct {
+		name         string
+		addr         string
+		allowPrivate bool
+		trusted      map[string]bool
+		want         string // Part of the error; empty when the dial succeeds
+	}{
+		{name: "loopback blocked", addr: addr, want: "non-public address"},
+		{name: "name resolving to loopback blocked", addr: net.JoinHostPort("localhost", port), want: "non-public address"},
+		{name: "private networks allowed", addr: addr, allowPrivate: true},
+		{name: "trusted proxy address", addr: addr, trusted: map[string]bool{addr: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dial := guardedDialer(&net.Dialer{}, tt.allowPrivate, tt.trusted)
+			conn, err := dial(context.Background(), "tcp", tt.addr)
+			if conn != nil {
+				conn.Close()
+			}
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package upstream
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit is the rate limit state Docker Hub reports on a response.
+type RateLimit struct {
+	Limit     int        `json:"limit"`
+	Remaining int        `json:"remaining"`
+	Reset     *time.Time `json:"reset,omitempty"`
+}
+
+// RateLimitFromHeader reads X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset, or the registry's RateLimit-Limit and RateLimit-Remaining
+// ("100;w=21600"). It returns nil when h has no rate limit headers.
+func RateLimitFromHeader(h http.Header) *RateLimit {
+	limit, okLimit := headerInt(h, "X-RateLimit-Limit", "RateLimit-Limit")
+	remaining, okRemaining := headerInt(h, "X-RateLimit-Remaining", "RateLimit-Remaining")
+	if !okLimit && !okRemaining {
+		return nil
+	}
+	rl := &RateLimit{Limit: limit, Remaining: remaining}
+	if reset, ok := headerInt(h, "X-RateLimit-Reset"); ok && reset > 0 {
+		t := time.Unix(int64(reset), 0).UTC()
+		rl.Reset = &t
+	}
+	return rl
+}
+
+// headerInt parses the leading integer of the first of names present in h.
+func headerInt(h http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		v := h.Get(name)
+		if v == "" {
+			continue
+		}
+		v, _, _ = strings.Cut(v, ";")
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		return n, err == nil
+	}
+	return 0, false
+}
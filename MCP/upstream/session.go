@@ -0,0 +1,318 @@
+package upstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/auth"
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/models"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Sessions is the login session manager shared by all tool handlers.
+// Besides tokens for configured credentials it holds, per MCP session, a
+// login that replaces the configured credentials (see APIConfig.SessionLogin).
+var Sessions = NewSessionManager()
+
+// SessionManager exchanges a username and password or personal access token
+// for a Hub JWT at /v2/users/login and caches it until shortly before it
+// expires, so credentials are only sent to the login endpoint.
+type SessionManager struct {
+	mu        sync.Mutex
+	tokens    map[sessionKey]session
+	loginLock map[sessionKey]*sync.Mutex // Serializes the logins for each key
+	logins    map[loginKey]SessionLogin  // Login used as the credential of an MCP session and client
+	twoFactor map[string]pendingLogin    // login_2fa_token -> login waiting for its code
+	now       func() time.Time
+}
+
+type sessionKey struct {
+	baseURL string
+	source  string
+}
+
+// loginKey binds a session login to the MCP session and the inbound client
+// that logged in, so a session ID alone does not carry the login.
+type loginKey struct {
+	session   string
+	principal string
+}
+
+// pendingLogin is a login waiting for its two-factor code.
+type pendingLogin struct {
+	username string
+	expires  time.Time
+}
+
+// twoFactorLifetime is how long a login_2fa_token is remembered.
+const twoFactorLifetime = 10 * time.Minute
+
+type session struct {
+	token   string
+	expires time.Time
+}
+
+// sessionRefreshMargin renews tokens this long before their "exp" claim.
+const sessionRefreshMargin = time.Minute
+
+// sessionDefaultLifetime is assumed for tokens without a readable "exp" claim.
+const sessionDefaultLifetime = 5 * time.Minute
+
+// NewSessionManager returns an empty session manager.
+func NewSessionManager() *SessionManager {
+	return &SessionManager{
+		tokens:    make(map[sessionKey]session),
+		loginLock: make(map[sessionKey]*sync.Mutex),
+		logins:    make(map[loginKey]SessionLogin),
+		twoFactor: make(map[string]pendingLogin),
+		now:       time.Now,
+	}
+}
+
+// Credentials returns the username and secret to log in with.
+type Credentials func(ctx context.Context) (username, secret string, err error)
+
+// Token returns a JWT for cfg.BaseURL, logging in with the credentials from
+// lookup when no unexpired token is cached for source. source names where the
+// credentials come from, e.g. the Docker config file, so lookup (which may run
+// a credential helper) is only called when a new token is needed.
+func (m *SessionManager) Token(ctx context.Context, cfg *config.APIConfig, source string, lookup Credentials) (string, error) {
+	key := sessionKey{baseURL: cfg.BaseURL, source: source}
+	if token, ok := m.cached(key); ok {
+		return token, nil
+	}
+	// Concurrent calls for the same credentials wait for one login instead of
+	// each logging in; logins with other credentials are not held up
+	lock := m.lockFor(key)
+	lock.Lock()
+	defer lock.Unlock()
+	if token, ok := m.cached(key); ok {
+		return token, nil
+	}
+
+	username, secret, err := lookup(ctx)
+	if err != nil {
+		return "", err
+	}
+	token, err := m.login(ctx, cfg, username, secret)
+	if err != nil {
+		return "", err
+	}
+	expires := m.now().Add(sessionDefaultLifetime)
+	if exp, ok := tokenExpiry(token); ok {
+		expires = exp.Add(-sessionRefreshMargin)
+	}
+	m.mu.Lock()
+	m.tokens[key] = session{token: token, expires: expires}
+	m.mu.Unlock()
+	return token, nil
+}
+
+func (m *SessionManager) lockFor(key sessionKey) *sync.Mutex {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lock, ok := m.loginLock[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		m.loginLock[key] = lock
+	}
+	return lock
+}
+
+func (m *SessionManager) cached(key sessionKey) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.tokens[key]
+	if !ok || !m.now().Before(s.expires) {
+		return "", false
+	}
+	return s.token, true
+}
+
+// Invalidate drops a cached token, e.g. after the API rejected it.
+func (m *SessionManager) Invalidate(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k, s := range m.tokens {
+		if s.token == token {
+			delete(m.tokens, k)
+		}
+	}
+}
+
+func (m *SessionManager) login(ctx context.Context, cfg *config.APIConfig, username, secret string) (string, error) {
+	body, err := json.Marshal(models.UsersLoginRequest{Username: username, Password: secret})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", cfg.BaseURL+"/v2/users/login", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	// The login request itself carries no credentials
+	loginCfg := *cfg
+	loginCfg.BearerToken, loginCfg.APIKey, loginCfg.BasicAuth, loginCfg.DockerConfig = "", "", "", ""
+	resp, err := Client(&loginCfg).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("login failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("login failed: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		var loginErr models.PostUsersLoginErrorResponse
+		if json.Unmarshal(data, &loginErr) == nil && loginErr.Login_2fa_token != "" {
+			return "", fmt.Errorf("login as %s requires two-factor authentication; use a personal access token instead of the password", username)
+		}
+		return "", fmt.Errorf("login as %s failed: %s: %s", username, resp.Status, data)
+	}
+	var result models.PostUsersLoginSuccessResponse
+	if err := json.Unmarshal(data, &result); err != nil || result.Token == "" {
+		return "", fmt.Errorf("login as %s returned no token", username)
+	}
+	return result.Token, nil
+}
+
+// tokenExpiry reads the unverified "exp" claim of a JWT.
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
+
+// SessionLogin is a login that became an MCP session's credential.
+type SessionLogin struct {
+	BaseURL  string    `json:"base_url"`
+	Username string    `json:"username,omitempty"`
+	Since    time.Time `json:"since"`
+	Expires  time.Time `json:"expires,omitempty"`
+	token    string
+}
+
+// loginKeyFor returns the key of the login of the MCP session and inbound
+// client in ctx, or false when ctx belongs to no session. Calls without
+// inbound authentication share the empty principal.
+func loginKeyFor(ctx context.Context) (loginKey, bool) {
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return loginKey{}, false
+	}
+	key := loginKey{session: session.SessionID()}
+	if p, ok := auth.PrincipalFromContext(ctx); ok {
+		key.principal = p.ID()
+	}
+	return key, true
+}
+
+// Login makes token the bearer credential of the MCP session and client in
+// ctx for requests to baseURL. It reports false when ctx belongs to no
+// session.
+func (m *SessionManager) Login(ctx context.Context, baseURL, username, token string) bool {
+	key, ok := loginKeyFor(ctx)
+	if !ok {
+		return false
+	}
+	login := SessionLogin{BaseURL: baseURL, Username: username, Since: m.now().UTC(), token: token}
+	if exp, ok := tokenExpiry(token); ok {
+		login.Expires = exp.UTC()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logins[key] = login
+	return true
+}
+
+// SessionLogin returns the unexpired login of the MCP session and client in ctx.
+func (m *SessionManager) SessionLogin(ctx context.Context) (SessionLogin, bool) {
+	key, ok := loginKeyFor(ctx)
+	if !ok {
+		return SessionLogin{}, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	login, ok := m.logins[key]
+	if ok && !login.Expires.IsZero() && !m.now().Before(login.Expires) {
+		delete(m.logins, key)
+		return SessionLogin{}, false
+	}
+	return login, ok
+}
+
+// Logout clears the login of the MCP session and client in ctx and returns it.
+func (m *SessionManager) Logout(ctx context.Context) (SessionLogin, bool) {
+	key, ok := loginKeyFor(ctx)
+	if !ok {
+		return SessionLogin{}, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	login, ok := m.logins[key]
+	delete(m.logins, key)
+	return login, ok
+}
+
+// Forget clears the logins of the session with the given ID, e.g. when it ends.
+func (m *SessionManager) Forget(sessionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.logins {
+		if key.session == sessionID {
+			delete(m.logins, key)
+		}
+	}
+}
+
+// ExpectTwoFactor remembers that the login of username is waiting for the
+// two-factor code of login2FAToken, so the login that completes it is
+// recorded with the username.
+func (m *SessionManager) ExpectTwoFactor(login2FAToken, username string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	for token, p := range m.twoFactor {
+		if !now.Before(p.expires) {
+			delete(m.twoFactor, token)
+		}
+	}
+	m.twoFactor[login2FAToken] = pendingLogin{username: username, expires: now.Add(twoFactorLifetime)}
+}
+
+// TwoFactorUsername returns the username whose login waits for the code of
+// login2FAToken, or "" when it is unknown, and forgets it.
+func (m *SessionManager) TwoFactorUsername(login2FAToken string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.twoFactor[login2FAToken]
+	delete(m.twoFactor, login2FAToken)
+	if !ok || !m.now().Before(p.expires) {
+		return ""
+	}
+	return p.username
+}
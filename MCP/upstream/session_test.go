@@ -0,0 +1,177 @@
+package upstream
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/auth"
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type testSession string
+
+func (s testSession) Initialize()                                         {}
+func (s testSession) Initialized() bool                                   { return true }
+func (s testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s testSession) SessionID() string                                   { return string(s) }
+
+func sessionContext(sessionID, subject string) context.Context {
+	ctx := context.Background()
+	if subject != "" {
+		ctx = auth.WithPrincipal(ctx, &auth.Principal{Method: "bearer", Subject: subject})
+	}
+	if sessionID != "" {
+		ctx = server.NewMCPServer("test", "1").WithContext(ctx, testSession(sessionID))
+	}
+	return ctx
+}
+
+func TestSessionLoginBinding(t *testing.T) {
+	m := NewSessionManager()
+	alice := sessionContext("s1", "alice")
+	if !m.Login(alice, "https://hub.docker.com", "alice", "jwt") {
+		t.Fatal("Login without effect")
+	}
+	if m.Login(context.Background(), "https://hub.docker.com", "alice", "jwt") {
+		t.Error("Login outside a session succeeded")
+	}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want bool
+	}{
+		{"same session and client", sessionContext("s1", "alice"), true},
+		{"same session, another client", sessionContext("s1", "mallory"), false},
+		{"same session, unauthenticated", sessionContext("s1", ""), false},
+		{"another session", sessionContext("s2", "alice"), false},
+		{"no session", context.Background(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			login, ok := m.SessionLogin(tt.ctx)
+			if ok != tt.want || ok && (login.Username != "alice" || login.token != "jwt") {
+				t.Errorf("got %+v, %v; want %v", login, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := m.Logout(sessionContext("s1", "mallory")); ok {
+		t.Error("another client logged the session out")
+	}
+	m.Forget("s1")
+	if _, ok := m.SessionLogin(alice); ok {
+		t.Error("login survived the end of its session")
+	}
+}
+
+func TestSessionLoginExpiry(t *testing.T) {
+	m := NewSessionManager()
+	now := time.Unix(1_800_000_000, 0)
+	m.now = func() time.Time { return now }
+	token := fmt.Sprintf("x.%s.y", b64JSON(map[string]any{"exp": now.Add(time.Hour).Unix()}))
+	ctx := sessionContext("s1", "")
+	m.Login(ctx, "https://hub.docker.com", "alice", token)
+	if login, ok := m.SessionLogin(ctx); !ok || !login.Expires.Equal(now.Add(time.Hour)) {
+		t.Fatalf("got %+v, %v", login, ok)
+	}
+	now = now.Add(time.Hour)
+	if _, ok := m.SessionLogin(ctx); ok {
+		t.Error("expired login is still used")
+	}
+}
+
+func TestTwoFactorUsername(t *testing.T) {
+	m := NewSessionManager()
+	now := time.Unix(1_800_000_000, 0)
+	m.now = func() time.Time { return now }
+	m.ExpectTwoFactor("2fa-1", "alice")
+	m.ExpectTwoFactor("2fa-2", "bob")
+	if got := m.TwoFactorUsername("2fa-1"); got != "alice" {
+		t.Errorf("got %q, want alice", got)
+	}
+	if got := m.TwoFactorUsername("2fa-1"); got != "" {
+		t.Errorf("second lookup got %q, want it forgotten", got)
+	}
+	if got := m.TwoFactorUsername("unknown"); got != "" {
+		t.Errorf("unknown token got %q", got)
+	}
+	now = now.Add(twoFactorLifetime)
+	if got := m.TwoFactorUsername("2fa-2"); got != "" {
+		t.Errorf("expired token got %q", got)
+	}
+}
+
+func TestTokenLocksPerCredential(t *testing.T) {
+	var logins atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logins.Add(1)
+		fmt.Fprint(w, `{"token":"jwt"}`)
+	}))
+	defer srv.Close()
+	cfg := &config.APIConfig{BaseURL: srv.URL, AllowedBaseURLs: []string{srv.URL}, AllowPrivateNetworks: true}
+	m := NewSessionManager()
+
+	// A login waiting for its credentials does not hold up other credentials
+	release := make(chan struct{})
+	slowDone := make(chan error)
+	go func() {
+		_, err := m.Token(context.Background(), cfg, "slow", func(context.Context) (string, string, error) {
+			<-release
+			return "slow", "secret", nil
+		})
+		slowDone <- err
+	}()
+	fast := make(chan error)
+	go func() {
+		_, err := m.Token(context.Background(), cfg, "fast", func(context.Context) (string, string, error) {
+			return "fast", "secret", nil
+		})
+		fast <- err
+	}()
+	select {
+	case err := <-fast:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("login with other credentials waited for a pending login")
+	}
+	close(release)
+	if err := <-slowDone; err != nil {
+		t.Fatal(err)
+	}
+
+	// Concurrent calls for the same credentials log in once
+	logins.Store(0)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.Token(context.Background(), cfg, "shared", func(context.Context) (string, string, error) {
+				return "shared", "secret", nil
+			}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := logins.Load(); n != 1 {
+		t.Errorf("logged in %d times, want 1", n)
+	}
+}
+
+func b64JSON(v any) string {
+	data, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
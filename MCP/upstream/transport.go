@@ -0,0 +1,149 @@
+package upstream
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+// transportKey identifies a transport; configs with equal keys share connections.
+type transportKey struct {
+	upstream     config.UpstreamConfig
+	allowPrivate bool
+}
+
+var (
+	transportsMu sync.Mutex
+	transports   = make(map[transportKey]*http.Transport)
+)
+
+// Transport returns the shared transport for cfg's upstream TLS, proxy and
+// private network settings, building it on first use.
+func Transport(cfg *config.APIConfig) (*http.Transport, error) {
+	key := transportKey{upstream: cfg.Upstream, allowPrivate: cfg.AllowPrivateNetworks}
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if t, ok := transports[key]; ok {
+		return t, nil
+	}
+	t, err := newTransport(key.upstream, key.allowPrivate)
+	if err != nil {
+		return nil, err
+	}
+	transports[key] = t
+	return t, nil
+}
+
+func newTransport(up config.UpstreamConfig, allowPrivate bool) (*http.Transport, error) {
+	tlsConfig, err := upstreamTLSConfig(up)
+	if err != nil {
+		return nil, err
+	}
+	proxy, proxyAddrs, err := upstreamProxy(up, allowPrivate)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           guardedDialer(dialer, allowPrivate, proxyAddrs),
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}, nil
+}
+
+// upstreamTLSConfig adds the CA bundle to the system roots and loads the
+// client certificate. It returns nil when neither is configured.
+func upstreamTLSConfig(up config.UpstreamConfig) (*tls.Config, error) {
+	if up.CAFile == "" && up.ClientCertFile == "" && up.ClientKeyFile == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if up.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(up.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read upstream CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("upstream CA bundle %s contains no PEM certificates", up.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if up.ClientCertFile != "" || up.ClientKeyFile != "" {
+		if up.ClientCertFile == "" || up.ClientKeyFile == "" {
+			return nil, fmt.Errorf("upstream client certificate and key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(up.ClientCertFile, up.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load upstream client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// upstreamProxy returns the transport's Proxy function and the proxy addresses
+// the guarded dialer may connect to. Proxies are explicit: the standard
+// HTTP_PROXY variables are ignored so upstream routing is never implicit.
+// Because the proxy resolves the API host, that host is checked here instead;
+// when it cannot be resolved locally, as is common behind corporate proxies,
+// the ALLOWED_BASE_URLS check is what constrains the request.
+func upstreamProxy(up config.UpstreamConfig, allowPrivate bool) (func(*http.Request) (*url.URL, error), map[string]bool, error) {
+	if up.HTTPProxy == "" && up.HTTPSProxy == "" {
+		return nil, nil, nil
+	}
+	trusted := make(map[string]bool)
+	for _, p := range []string{up.HTTPProxy, up.HTTPSProxy} {
+		if p == "" {
+			continue
+		}
+		if !strings.Contains(p, "://") {
+			p = "http://" + p
+		}
+		u, err := url.Parse(p)
+		if err != nil || u.Host == "" {
+			return nil, nil, fmt.Errorf("invalid upstream proxy URL %q", p)
+		}
+		trusted[strings.TrimPrefix(origin(u), strings.ToLower(u.Scheme)+"://")] = true
+	}
+
+	proxyFor := (&httpproxy.Config{
+		HTTPProxy:  up.HTTPProxy,
+		HTTPSProxy: up.HTTPSProxy,
+		NoProxy:    up.NoProxy,
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		proxyURL, err := proxyFor(req.URL)
+		if err != nil || proxyURL == nil {
+			return proxyURL, err
+		}
+		if !allowPrivate {
+			_, err := resolveChecked(req.Context(), req.URL.Hostname(), false)
+			var dnsErr *net.DNSError
+			if err != nil && !errors.As(err, &dnsErr) {
+				return nil, err
+			}
+		}
+		return proxyURL, nil
+	}, trusted, nil
+}
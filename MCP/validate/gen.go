@@ -0,0 +1,383 @@
+//go:build ignore
+
+// gen writes spec_gen.go, the argument schemas of every operation in the
+// OpenAPI spec, keyed by the tool name generated for the operation.
+//
+// Besides the formal schema keywords it picks up constraints the spec only
+// states in prose or examples:
+//   - "Max of N" in a description sets the maximum
+//   - "Defaults to N." in a description sets the default
+//   - `Valid <name>: "a", "b"` in an array's description sets the item enum
+//   - a string whose example is an RFC 3339 time is a date-time, and so are
+//     parameters sharing its name
+//   - page and page_size start at 1
+//   - a string's numeric or boolean example, e.g. a 2FA code of 123456, is
+//     written as a string
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type node = map[string]any
+
+var (
+	maxOf      = regexp.MustCompile(`Max of (\d+)`)
+	defaultsTo = regexp.MustCompile(`Defaults to (\d+)\.`)
+	validList  = regexp.MustCompile(`Valid \w+: ((?:"[^"]+"(?:, )?)+)`)
+	quotedItem = regexp.MustCompile(`"([^"]+)"`)
+	methods    = []string{"get", "put", "post", "delete", "patch", "head"}
+)
+
+type schema struct {
+	Type        string
+	Format      string
+	Description string
+	Default     any
+	Examples    []any
+	Enum        []string
+	Minimum     *float64
+	Maximum     *float64
+	Items       *schema
+	Properties  map[string]*schema
+	Required    []string
+}
+
+type operation struct {
+	tool, method, path string
+	args               *schema
+}
+
+func main() {
+	specFile := flag.String("spec", "../../opeanapi.yaml", "OpenAPI spec")
+	out := flag.String("out", "spec_gen.go", "output file")
+	flag.Parse()
+
+	data, err := os.ReadFile(*specFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var spec node
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		log.Fatal(err)
+	}
+	g := &generator{spec: spec, timeNames: make(map[string]bool)}
+
+	var ops []operation
+	paths := spec["paths"].(node)
+	for path, item := range paths {
+		item := item.(node)
+		for _, method := range methods {
+			op, ok := item[method].(node)
+			if !ok {
+				continue
+			}
+			ops = append(ops, g.operation(path, method, item, op))
+		}
+	}
+	// Names of time-valued arguments are only known after every operation was read
+	for _, op := range ops {
+		for name, s := range op.args.Properties {
+			if g.timeNames[name] && s.Type == "string" && s.Format == "" {
+				s.Format = "date-time"
+			}
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].tool < ops[j].tool })
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gen.go from opeanapi.yaml; DO NOT EDIT.\n\npackage validate\n\nvar operations = map[string]Operation{\n")
+	for _, op := range ops {
+		fmt.Fprintf(&buf, "%q: {Method: %q, Path: %q, Args: %s},\n", op.tool, strings.ToUpper(op.method), op.path, literal(op.args))
+	}
+	buf.WriteString("}\n")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("format: %v\n%s", err, buf.Bytes())
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type generator struct {
+	spec      node
+	timeNames map[string]bool
+}
+
+// toolName mirrors the tool generator: "get /v2/orgs/{name}/settings" becomes
+// "get_v2_orgs_name_settings".
+func toolName(method, path string) string {
+	path = strings.NewReplacer("{", "", "}", "").Replace(strings.TrimPrefix(path, "/"))
+	return method + "_" + strings.ReplaceAll(path, "/", "_")
+}
+
+func (g *generator) operation(path, method string, item, op node) operation {
+	args := &schema{Type: "object", Properties: make(map[string]*schema)}
+	params, _ := item["parameters"].([]any)
+	opParams, _ := op["parameters"].([]any)
+	for _, p := range append(params, opParams...) {
+		p := g.resolve(p.(node))
+		name := p["name"].(string)
+		s := g.schema(p["schema"].(node), str(p["description"]))
+		if example, ok := p["example"]; ok && len(s.Examples) == 0 {
+			s.Examples = []any{example}
+			s.quoteExamples()
+		}
+		if name == "page" || name == "page_size" {
+			s.Minimum = ptr(1)
+		}
+		if s.Format == "date-time" {
+			g.timeNames[name] = true
+		}
+		args.Properties[name] = s
+		if p["required"] == true || p["in"] == "path" {
+			args.Required = append(args.Required, name)
+		}
+	}
+	if body, ok := op["requestBody"].(node); ok {
+		content := g.resolve(body)["content"].(node)
+		if media, ok := content["application/json"].(node); ok {
+			bodySchema := g.schema(media["schema"].(node), "")
+			for name, s := range bodySchema.Properties {
+				args.Properties[name] = s
+			}
+			args.Required = append(args.Required, bodySchema.Required...)
+		}
+	}
+	sort.Strings(args.Required)
+	return operation{tool: toolName(method, path), method: method, path: path, args: args}
+}
+
+// resolve follows a local $ref.
+func (g *generator) resolve(n node) node {
+	ref, ok := n["$ref"].(string)
+	if !ok {
+		return n
+	}
+	var cur any = g.spec
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		cur = cur.(node)[part]
+	}
+	return g.resolve(cur.(node))
+}
+
+func (g *generator) schema(n node, description string) *schema {
+	n = g.resolve(n)
+	s := &schema{Type: str(n["type"]), Format: str(n["format"]), Default: n["default"]}
+	if description == "" {
+		description = str(n["description"])
+	}
+	s.Description = strings.TrimSpace(description)
+	if example, ok := n["example"]; ok {
+		s.Examples = []any{example}
+	}
+	for _, part := range list(n["allOf"]) {
+		merge(s, g.schema(part.(node), ""))
+	}
+	for _, v := range list(n["enum"]) {
+		s.Enum = append(s.Enum, fmt.Sprint(v))
+	}
+	if v, ok := number(n["minimum"]); ok {
+		s.Minimum = ptr(v)
+	}
+	if v, ok := number(n["maximum"]); ok {
+		s.Maximum = ptr(v)
+	}
+	if m := maxOf.FindStringSubmatch(description); m != nil && s.Maximum == nil {
+		v, _ := strconv.ParseFloat(m[1], 64)
+		s.Maximum = ptr(v)
+	}
+	if m := defaultsTo.FindStringSubmatch(description); m != nil && s.Default == nil {
+		v, _ := strconv.Atoi(m[1])
+		s.Default = v
+	}
+	if items, ok := n["items"].(node); ok {
+		s.Items = g.schema(items, "")
+		if m := validList.FindStringSubmatch(description); m != nil && len(s.Items.Enum) == 0 {
+			for _, q := range quotedItem.FindAllStringSubmatch(m[1], -1) {
+				s.Items.Enum = append(s.Items.Enum, q[1])
+			}
+		}
+	}
+	if props, ok := n["properties"].(node); ok {
+		if s.Properties == nil {
+			s.Properties = make(map[string]*schema)
+		}
+		for name, p := range props {
+			prop := g.schema(p.(node), "")
+			if prop.Type == "string" && prop.Format == "" && isTime(p.(node)["example"]) {
+				prop.Format = "date-time"
+			}
+			if prop.Format == "date-time" {
+				g.timeNames[name] = true
+			}
+			s.Properties[name] = prop
+		}
+	}
+	for _, r := range list(n["required"]) {
+		s.Required = append(s.Required, r.(string))
+	}
+	if s.Type == "" && s.Properties != nil {
+		s.Type = "object"
+	}
+	s.quoteExamples()
+	return s
+}
+
+// quoteExamples writes the numeric and boolean examples of a string schema as
+// strings, so they validate against it. YAML reads an unquoted 123456 as a
+// number.
+func (s *schema) quoteExamples() {
+	if s.Type != "string" {
+		return
+	}
+	for i, e := range s.Examples {
+		switch e.(type) {
+		case int, float64, bool:
+			s.Examples[i] = fmt.Sprint(e)
+		}
+	}
+}
+
+func merge(dst, src *schema) {
+	if dst.Type == "" {
+		dst.Type = src.Type
+	}
+	if dst.Description == "" {
+		dst.Description = src.Description
+	}
+	if len(dst.Examples) == 0 {
+		dst.Examples = src.Examples
+	}
+	if src.Properties != nil && dst.Properties == nil {
+		dst.Properties = make(map[string]*schema)
+	}
+	for name, p := range src.Properties {
+		dst.Properties[name] = p
+	}
+	dst.Required = append(dst.Required, src.Required...)
+}
+
+func isTime(v any) bool {
+	switch t := v.(type) {
+	case time.Time:
+		return true
+	case string:
+		_, err := time.Parse(time.RFC3339, t)
+		return err == nil
+	}
+	return false
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+func list(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func ptr(v float64) *float64 { return &v }
+
+// literal renders s as a Go composite literal.
+func literal(s *schema) string {
+	var parts []string
+	if s.Type != "" {
+		parts = append(parts, fmt.Sprintf("Type: %q", s.Type))
+	}
+	if s.Format != "" {
+		parts = append(parts, fmt.Sprintf("Format: %q", s.Format))
+	}
+	if s.Description != "" {
+		parts = append(parts, fmt.Sprintf("Description: %q", s.Description))
+	}
+	if s.Default != nil {
+		parts = append(parts, "Default: "+value(s.Default))
+	}
+	if len(s.Examples) > 0 {
+		examples := make([]string, len(s.Examples))
+		for i, e := range s.Examples {
+			examples[i] = value(e)
+		}
+		parts = append(parts, "Examples: []any{"+strings.Join(examples, ", ")+"}")
+	}
+	if len(s.Enum) > 0 {
+		parts = append(parts, fmt.Sprintf("Enum: %#v", s.Enum))
+	}
+	if s.Minimum != nil {
+		parts = append(parts, fmt.Sprintf("Minimum: float(%v)", *s.Minimum))
+	}
+	if s.Maximum != nil {
+		parts = append(parts, fmt.Sprintf("Maximum: float(%v)", *s.Maximum))
+	}
+	if s.Items != nil {
+		parts = append(parts, "Items: "+literal(s.Items))
+	}
+	if len(s.Properties) > 0 {
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var props []string
+		for _, name := range names {
+			props = append(props, fmt.Sprintf("%q: %s", name, literal(s.Properties[name])))
+		}
+		parts = append(parts, "Properties: map[string]*Schema{\n"+strings.Join(props, ",\n")+",\n}")
+	}
+	if len(s.Required) > 0 {
+		parts = append(parts, fmt.Sprintf("Required: %#v", s.Required))
+	}
+	return "&Schema{" + strings.Join(parts, ", ") + "}"
+}
+
+// value renders a default or example decoded from YAML as a Go expression.
+func value(v any) string {
+	switch t := v.(type) {
+	case time.Time:
+		return strconv.Quote(t.Format(time.RFC3339))
+	case []any:
+		items := make([]string, len(t))
+		for i, item := range t {
+			items[i] = value(item)
+		}
+		return "[]any{" + strings.Join(items, ", ") + "}"
+	case node:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fields := make([]string, len(keys))
+		for i, k := range keys {
+			fields[i] = fmt.Sprintf("%q: %s", k, value(t[k]))
+		}
+		return "map[string]any{" + strings.Join(fields, ", ") + "}"
+	}
+	return fmt.Sprintf("%#v", v)
+}
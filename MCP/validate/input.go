@@ -0,0 +1,84 @@
+package validate
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/invopop/jsonschema"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// JSONSchema renders s as a JSON Schema for tool input schemas, with nested
+// item and property schemas, enums, defaults, examples and bounds.
+//
+// Date-time strings carry no format: tools also accept expressions such as
+// "7d" or "yesterday", which clients checking the format would reject.
+func (s *Schema) JSONSchema() *jsonschema.Schema {
+	js := &jsonschema.Schema{
+		Type:        s.Type,
+		Description: s.Description,
+		Default:     s.Default,
+		Examples:    s.Examples,
+		Required:    s.Required,
+	}
+	if s.Format != "date-time" {
+		js.Format = s.Format
+	}
+	for _, v := range s.Enum {
+		js.Enum = append(js.Enum, v)
+	}
+	if s.Minimum != nil {
+		js.Minimum = number(*s.Minimum)
+	}
+	if s.Maximum != nil {
+		js.Maximum = number(*s.Maximum)
+	}
+	if s.Items != nil {
+		js.Items = s.Items.JSONSchema()
+	}
+	if len(s.Properties) > 0 {
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		js.Properties = jsonschema.NewProperties()
+		for _, name := range names {
+			js.Properties.Set(name, s.Properties[name].JSONSchema())
+		}
+	}
+	return js
+}
+
+// Describe replaces the input schema properties of a tool generated from the
+// spec with their full spec schemas. Descriptions written for the tool are
+// kept; properties the spec does not describe, such as "profile", are left
+// alone.
+func Describe(tool *mcp.Tool) {
+	op, ok := Lookup(tool.Name)
+	if !ok {
+		return
+	}
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = make(map[string]any)
+	}
+	for name, s := range op.Args.Properties {
+		js := s.JSONSchema()
+		if prop, ok := tool.InputSchema.Properties[name].(map[string]any); ok {
+			if description, _ := prop["description"].(string); description != "" {
+				js.Description = description
+			}
+		}
+		tool.InputSchema.Properties[name] = js
+	}
+	for _, name := range op.Args.Required {
+		if !contains(tool.InputSchema.Required, name) {
+			tool.InputSchema.Required = append(tool.InputSchema.Required, name)
+		}
+	}
+}
+
+func number(v float64) json.Number {
+	return json.Number(strconv.FormatFloat(v, 'f', -1, 64))
+}
@@ -0,0 +1,37 @@
+// Package validate checks and coerces tool arguments against the parameter and
+// request body schemas of opeanapi.yaml before any request is sent.
+package validate
+
+//go:generate go run gen.go -spec ../../opeanapi.yaml -out spec_gen.go
+
+// Schema is the subset of an OpenAPI schema that validation and tool input
+// schemas use.
+type Schema struct {
+	Type        string // string, integer, number, boolean, array or object; empty accepts anything
+	Format      string // e.g. date-time or int32
+	Description string
+	Default     any
+	Examples    []any
+	Enum        []string
+	Minimum     *float64
+	Maximum     *float64
+	Items       *Schema
+	Properties  map[string]*Schema
+	Required    []string
+}
+
+// Operation describes the arguments of one tool: its path and query
+// parameters and the top-level properties of its request body.
+type Operation struct {
+	Method string
+	Path   string
+	Args   *Schema
+}
+
+// Lookup returns the operation behind the named tool.
+func Lookup(tool string) (Operation, bool) {
+	op, ok := operations[tool]
+	return op, ok
+}
+
+func float(v float64) *float64 { return &v }
@@ -0,0 +1,320 @@
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/config"
+	"github.com/docker-hub-api/mcp-server/times"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Errors maps argument paths, such as "page_size" or "manifests[0].digest",
+// to what is wrong with them.
+type Errors map[string][]string
+
+func (e Errors) add(path, format string, a ...any) {
+	e[path] = append(e[path], fmt.Sprintf(format, a...))
+}
+
+// Error lists the problems ordered by argument path.
+func (e Errors) Error() string {
+	paths := make([]string, 0, len(e))
+	for path := range e {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	parts := make([]string, 0, len(paths))
+	for _, path := range paths {
+		parts = append(parts, path+": "+strings.Join(e[path], ", "))
+	}
+	return "Invalid arguments: " + strings.Join(parts, "; ")
+}
+
+// Result returns e as an error tool result with the field errors as
+// structured content.
+func (e Errors) Result() *mcp.CallToolResult {
+	result := mcp.NewToolResultStructured(map[string]any{"message": "Invalid arguments", "fields": e}, e.Error())
+	result.IsError = true
+	return result
+}
+
+// checker coerces values and collects their problems.
+type checker struct {
+	errs Errors
+	now  time.Time
+	loc  *time.Location // Zone of date-time arguments without an offset
+}
+
+// Arguments checks args against the schema of op and returns a copy with
+// values coerced to their documented types, e.g. "25" to 25 for integers and
+// "true" to true for booleans. Date-time arguments accept the expressions of
+// times.Parse, read in loc, and are sent as RFC 3339 in UTC. Arguments the
+// schema does not describe, such as "profile", are passed through unchanged.
+// Path parameters must be single, non-empty segments other than "." and "..".
+func Arguments(op Operation, args map[string]any, loc *time.Location) (map[string]any, Errors) {
+	c := &checker{errs: make(Errors), now: time.Now(), loc: loc}
+	errs := c.errs
+	out := make(map[string]any, len(args))
+	for name, value := range args {
+		out[name] = value
+	}
+	for _, name := range op.Args.Required {
+		if v, ok := args[name]; !ok || v == nil {
+			errs.add(name, "is required")
+		}
+	}
+	for name, schema := range op.Args.Properties {
+		value, ok := args[name]
+		if !ok || value == nil {
+			continue
+		}
+		out[name] = c.coerce(schema, value, name)
+	}
+	for _, name := range pathParameters(op.Path) {
+		if value, ok := out[name].(string); ok {
+			checkSegment(errs, name, value)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return out, nil
+}
+
+var pathParameter = regexp.MustCompile(`\{([^}]+)\}`)
+
+// pathParameters returns the names of the parameters in path, such as
+// "namespace" in "/v2/namespaces/{namespace}/delete-images".
+func pathParameters(path string) []string {
+	var names []string
+	for _, m := range pathParameter.FindAllStringSubmatch(path, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// checkSegment rejects path parameter values that would address another
+// endpoint than the tool's, such as "../../orgs/acme" for a repository, even
+// once escaped: a proxy or the API may decode "%2F" or resolve "..".
+func checkSegment(errs Errors, name, value string) {
+	switch {
+	case value == "":
+		errs.add(name, "must not be empty")
+	case value == "." || value == "..":
+		errs.add(name, "must not be %q", value)
+	case strings.ContainsAny(value, "/\\"):
+		errs.add(name, "must not contain \"/\" or \"\\\"")
+	}
+}
+
+// coerce converts value to the type of s and checks its constraints, adding
+// problems under path.
+func (c *checker) coerce(s *Schema, value any, path string) any {
+	errs := c.errs
+	switch s.Type {
+	case "integer", "number":
+		n, ok := toNumber(value)
+		if !ok {
+			errs.add(path, "must be a number")
+			return value
+		}
+		if s.Type == "integer" && n != math.Trunc(n) {
+			errs.add(path, "must be an integer")
+			return value
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			errs.add(path, "must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			errs.add(path, "must be at most %v", *s.Maximum)
+		}
+		if s.Format == "int32" && (n < math.MinInt32 || n > math.MaxInt32) {
+			errs.add(path, "must fit in 32 bits")
+		}
+		// Whole numbers are sent as integers, never as "1e+06"
+		if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+			return int64(n)
+		}
+		return n
+
+	case "boolean":
+		switch v := value.(type) {
+		case bool:
+			return v
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "1", "yes":
+				return true
+			case "false", "0", "no":
+				return false
+			}
+		case float64:
+			if v == 0 || v == 1 {
+				return v == 1
+			}
+		}
+		errs.add(path, "must be true or false")
+		return value
+
+	case "string":
+		var str string
+		switch v := value.(type) {
+		case string:
+			str = v
+		case float64:
+			str = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			str = strconv.FormatBool(v)
+		case json.Number:
+			str = v.String()
+		default:
+			errs.add(path, "must be a string")
+			return value
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			errs.add(path, "must be one of %s", quoteList(s.Enum))
+		}
+		if s.Format == "date-time" {
+			normalized, err := times.Normalize(str, c.now, c.loc)
+			if err != nil {
+				errs.add(path, "must be a time such as %s", times.Examples)
+				return str
+			}
+			return normalized
+		}
+		return str
+
+	case "array":
+		items, ok := toArray(value, s.Items)
+		if !ok {
+			errs.add(path, "must be an array")
+			return value
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				items[i] = c.coerce(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+		return items
+
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			// Models sometimes pass nested objects as JSON text
+			if str, isString := value.(string); !isString || json.Unmarshal([]byte(str), &obj) != nil {
+				errs.add(path, "must be an object")
+				return value
+			}
+		}
+		out := make(map[string]any, len(obj))
+		for name, v := range obj {
+			out[name] = v
+		}
+		for _, name := range s.Required {
+			if v, ok := obj[name]; !ok || v == nil {
+				errs.add(path+"."+name, "is required")
+			}
+		}
+		for name, prop := range s.Properties {
+			if v, ok := obj[name]; ok && v != nil {
+				out[name] = c.coerce(prop, v, path+"."+name)
+			}
+		}
+		return out
+	}
+	return value
+}
+
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil && !math.IsInf(n, 0) && !math.IsNaN(n)
+	}
+	return 0, false
+}
+
+// toArray accepts an array, an array passed as JSON text, or a comma-separated
+// string when the items are strings.
+func toArray(value any, items *Schema) ([]any, bool) {
+	switch v := value.(type) {
+	case []any:
+		return append([]any(nil), v...), true
+	case []string:
+		out := make([]any, len(v))
+		for i, s := range v {
+			out[i] = s
+		}
+		return out, true
+	case string:
+		var out []any
+		if json.Unmarshal([]byte(v), &out) == nil {
+			return out, true
+		}
+		if items != nil && items.Type == "string" {
+			for _, part := range strings.Split(v, ",") {
+				if part = strings.TrimSpace(part); part != "" {
+					out = append(out, part)
+				}
+			}
+			return out, true
+		}
+	}
+	return nil, false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteList(list []string) string {
+	quoted := make([]string, len(list))
+	for i, s := range list {
+		quoted[i] = strconv.Quote(s)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Middleware returns a middleware that validates and coerces the arguments of
+// tools generated from the spec before their handler runs, reading times in
+// the timezone of the effective APIConfig.
+func Middleware(base *config.APIConfig) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			op, ok := Lookup(request.Params.Name)
+			if !ok {
+				return next(ctx, request)
+			}
+			args, _ := request.Params.Arguments.(map[string]any)
+			coerced, errs := Arguments(op, args, times.Location(config.FromContext(ctx, base)))
+			if errs != nil {
+				return errs.Result(), nil
+			}
+			request.Params.Arguments = coerced
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package validate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArgumentsCoerce(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want map[string]any
+	}{
+		{
+			name: "numbers from strings",
+			tool: "get_v2_namespaces_namespace_repositories_repository_tags",
+			args: map[string]any{"namespace": "acme", "repository": "web", "page": "2", "page_size": 25.0},
+			want: map[string]any{"namespace": "acme", "repository": "web", "page": int64(2), "page_size": int64(25)},
+		},
+		{
+			name: "numbers to strings",
+			tool: "get_v2_namespaces_namespace_repositories_repository_tags",
+			args: map[string]any{"namespace": 42.0, "repository": "web"},
+			want: map[string]any{"namespace": "42", "repository": "web"},
+		},
+		{
+			name: "array as JSON text",
+			tool: "post_v2_namespaces_namespace_delete-images",
+			args: map[string]any{"namespace": "acme", "manifests": `[{"repository":"web","digest":"sha256:1"}]`},
+			want: map[string]any{"namespace": "acme", "manifests": []any{map[string]any{"repository": "web", "digest": "sha256:1"}}},
+		},
+		{
+			name: "boolean words",
+			tool: "post_v2_namespaces_namespace_delete-images",
+			args: map[string]any{"namespace": "acme", "dry_run": "yes"},
+			want: map[string]any{"namespace": "acme", "dry_run": true},
+		},
+		{
+			name: "arguments outside the spec pass through",
+			tool: "get_v2_namespaces_namespace_repositories_repository_tags",
+			args: map[string]any{"namespace": "acme", "repository": "web", "profile": "ci", "format": "csv"},
+			want: map[string]any{"namespace": "acme", "repository": "web", "profile": "ci", "format": "csv"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, ok := Lookup(tt.tool)
+			if !ok {
+				t.Fatalf("no operation for %s", tt.tool)
+			}
+			got, errs := Arguments(op, tt.args, time.UTC)
+			if errs != nil {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgumentsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want map[string]string // Argument path -> part of its problem
+	}{
+		{
+			name: "missing required",
+			tool: "get_v2_namespaces_namespace_repositories_repository_tags",
+			args: map[string]any{"namespace": "acme"},
+			want: map[string]string{"repository": "is required"},
+		},
+		{
+			name: "range",
+			tool: "get_v2_namespaces_namespace_repositories_repository_tags",
+			args: map[string]any{"namespace": "acme", "repository": "web", "page_size": 101.0, "page": 0.0},
+			want: map[string]string{"page_size": "at most 100", "page": "at least 1"},
+		},
+		{
+			name: "not an integer",
+			tool: "get_v2_namespaces_namespace_repositories_repository_tags",
+			args: map[string]any{"namespace": "acme", "repository": "web", "page": "1.5"},
+			want: map[string]string{"page": "must be an integer"},
+		},
+		{
+			name: "array that is not JSON",
+			tool: "post_v2_namespaces_namespace_delete-images",
+			args: map[string]any{"namespace": "acme", "manifests": "web@sha256:1"},
+			want: map[string]string{"manifests": "must be an array"},
+		},
+		{
+			name: "nested required and enum",
+			tool: "post_v2_namespaces_namespace_delete-images",
+			args: map[string]any{"namespace": "acme", "ignore_warnings": []any{map[string]any{"repository": "web", "digest": "sha256:1", "warning": "other"}}, "manifests": []any{map[string]any{"repository": "web"}}},
+			want: map[string]string{"ignore_warnings[0].warning": "must be one of", "manifests[0].digest": "is required"},
+		},
+		{
+			name: "time",
+			tool: "post_v2_namespaces_namespace_delete-images",
+			args: map[string]any{"namespace": "acme", "active_from": "last tuesday"},
+			want: map[string]string{"active_from": "must be a time"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, _ := Lookup(tt.tool)
+			_, errs := Arguments(op, tt.args, time.UTC)
+			if len(errs) != len(tt.want) {
+				t.Fatalf("got errors %v, want %d", errs, len(tt.want))
+			}
+			for path, want := range tt.want {
+				if got := strings.Join(errs[path], ", "); !strings.Contains(got, want) {
+					t.Errorf("%s: got %q, want %q", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestArgumentsPathParameters(t *testing.T) {
+	tests := []struct {
+		tool  string
+		name  string
+		value any
+		want  string // Part of the problem; empty when the value is accepted
+	}{
+		{"get_v2_namespaces_namespace_repositories_repository_tags", "repository", "web", ""},
+		{"get_v2_namespaces_namespace_repositories_repository_tags", "repository", "web.app", ""},
+		{"get_v2_namespaces_namespace_repositories_repository_tags", "repository", "../../other/repositories/x", "must not contain"},
+		{"get_v2_namespaces_namespace_repositories_repository_tags", "repository", "..", "must not be"},
+		{"get_v2_namespaces_namespace_repositories_repository_tags", "namespace", ".", "must not be"},
+		{"get_v2_namespaces_namespace_repositories_repository_tags", "namespace", "", "must not be empty"},
+		{"get_v2_namespaces_namespace_repositories_repository_tags", "namespace", `acme\web`, "must not contain"},
+		{"get_v2_access-tokens_uuid", "uuid", "../../orgs/acme/settings", "must not contain"},
+		{"get_v2_orgs_name_settings", "name", "acme/..", "must not contain"},
+		{"get_v2_auditlogs_account", "account", "acme%2F..", ""},
+	}
+	for _, tt := range tests {
+		op, _ := Lookup(tt.tool)
+		args := map[string]any{}
+		for _, name := range op.Args.Required {
+			args[name] = "ok"
+		}
+		args[tt.name] = tt.value
+		_, errs := Arguments(op, args, time.UTC)
+		got := strings.Join(errs[tt.name], ", ")
+		if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+			t.Errorf("%s %s=%q: got %q, want %q", tt.tool, tt.name, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestArgumentsNormalizesTimes(t *testing.T) {
+	op, _ := Lookup("post_v2_namespaces_namespace_delete-images")
+	got, errs := Arguments(op, map[string]any{"namespace": "acme", "active_from": "2026-10-01"}, time.UTC)
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	if got["active_from"] != "2026-10-01T00:00:00Z" {
+		t.Errorf("got %v", got["active_from"])
+	}
+}
+
+func TestToArray(t *testing.T) {
+	strs := &Schema{Type: "string"}
+	tests := []struct {
+		value any
+		items *Schema
+		want  []any
+		ok    bool
+	}{
+		{[]any{"a"}, strs, []any{"a"}, true},
+		{[]string{"a", "b"}, strs, []any{"a", "b"}, true},
+		{`["a","b"]`, strs, []any{"a", "b"}, true},
+		{"a, b,,", strs, []any{"a", "b"}, true},
+		{"a, b", &Schema{Type: "object"}, nil, false},
+		{42.0, strs, nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := toArray(tt.value, tt.items)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("toArray(%#v) = %#v, %v; want %#v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestExamplesMatchTypes(t *testing.T) {
+	var check func(t *testing.T, path string, s *Schema)
+	check = func(t *testing.T, path string, s *Schema) {
+		for _, e := range s.Examples {
+			var ok bool
+			switch s.Type {
+			case "string":
+				_, ok = e.(string)
+			case "integer", "number":
+				_, ok = toNumber(e)
+			case "boolean":
+				_, ok = e.(bool)
+			default:
+				ok = true
+			}
+			if !ok {
+				t.Errorf("%s: example %#v is not a %s", path, e, s.Type)
+			}
+		}
+		if s.Items != nil {
+			check(t, path+"[]", s.Items)
+		}
+		for name, p := range s.Properties {
+			check(t, path+"."+name, p)
+		}
+	}
+	for tool, op := range operations {
+		check(t, tool, op.Args)
+	}
+
+	tests := []struct {
+		tool, arg string
+		want      any
+	}{
+		{"post_v2_users_2fa-login", "code", "123456"},
+		{"post_v2_users_login", "username", "myusername"},
+	}
+	for _, tt := range tests {
+		op, _ := Lookup(tt.tool)
+		if s := op.Args.Properties[tt.arg]; s == nil || len(s.Examples) == 0 || s.Examples[0] != tt.want {
+			t.Errorf("%s %s: examples %#v, want %#v", tt.tool, tt.arg, s, tt.want)
+		}
+	}
+}
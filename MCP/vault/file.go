@@ -0,0 +1,99 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// KeySize is the length of the AES-256 key protecting the vault file.
+const KeySize = 32
+
+// fileStore keeps the vault in a file as nonce || AES-256-GCM(JSON).
+type fileStore struct {
+	path string
+	aead cipher.AEAD
+}
+
+// Open returns a vault persisted to path, encrypted with key, loading any
+// secrets already stored there.
+func Open(path string, key []byte) (*Vault, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("vault key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	store := &fileStore{path: path, aead: aead}
+	secrets, err := store.load()
+	if err != nil {
+		return nil, err
+	}
+	v := newVault(secrets)
+	v.store = store
+	return v, nil
+}
+
+func (f *fileStore) load() (map[string]Secret, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return make(map[string]Secret), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault file: %w", err)
+	}
+	n := f.aead.NonceSize()
+	if len(data) < n {
+		return nil, fmt.Errorf("vault file %s is truncated", f.path)
+	}
+	plain, err := f.aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt vault file %s: wrong key or corrupted file", f.path)
+	}
+	secrets := make(map[string]Secret)
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse vault file %s: %w", f.path, err)
+	}
+	return secrets, nil
+}
+
+// save replaces the file atomically so a crash never leaves a partial vault.
+func (f *fileStore) save(secrets map[string]Secret) error {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, f.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data := f.aead.Seal(nonce, nonce, plain, nil)
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".vault-*")
+	if err != nil {
+		return fmt.Errorf("failed to write vault file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write vault file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write vault file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to write vault file: %w", err)
+	}
+	return nil
+}
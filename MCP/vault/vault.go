@@ -0,0 +1,190 @@
+// Package vault keeps secrets returned by the API, such as new personal access
+// tokens and login JWTs, on the server. Tools hand out opaque handles instead
+// of the plaintext so secrets do not end up in model transcripts.
+package vault
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/auth"
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+// HandlePrefix starts every handle, so handles are recognizable in tool results.
+const HandlePrefix = "vault:"
+
+// ErrNotFound is returned for unknown handles and for secrets owned by another client.
+var ErrNotFound = errors.New("secret not found")
+
+// Secret is one stored value with the metadata shown alongside its handle.
+type Secret struct {
+	Value       string    `json:"value"`
+	Kind        string    `json:"kind"`        // e.g. "access_token" or "login_token"
+	Description string    `json:"description"` // e.g. the token label or username
+	Owner       string    `json:"owner"`       // ID of the client that created it; empty without inbound auth
+	Created     time.Time `json:"created"`
+}
+
+// Vault stores secrets in memory and, when a file is configured, persists
+// them encrypted after every change. Secrets expire TTL after they were
+// stored, and the oldest are dropped once there are more than MaxSecrets.
+type Vault struct {
+	mu      sync.RWMutex
+	secrets map[string]Secret
+	store   *fileStore
+	now     func() time.Time
+
+	AllowReveal bool          // reveal_secret may return plaintext in the tool result
+	ExportDir   string        // Directory reveal_secret may write secrets to; empty disables file export
+	TTL         time.Duration // How long a secret is kept; 0 keeps it until deleted
+	MaxSecrets  int           // Secrets kept; 0 means no limit
+}
+
+// RevealEnabled reports whether the reveal_secret tool has anything to offer.
+func (v *Vault) RevealEnabled() bool {
+	return v.AllowReveal || v.ExportDir != ""
+}
+
+// Default is the vault used by tool handlers. main replaces it with a
+// persistent vault when VAULT_FILE is set.
+var Default = New()
+
+// New returns an empty in-memory vault with the default limits.
+func New() *Vault {
+	return newVault(make(map[string]Secret))
+}
+
+func newVault(secrets map[string]Secret) *Vault {
+	cfg := config.DefaultVaultConfig()
+	return &Vault{secrets: secrets, now: time.Now, TTL: cfg.TTL, MaxSecrets: cfg.MaxSecrets}
+}
+
+// Put stores s for the client in ctx and returns its handle.
+func (v *Vault) Put(ctx context.Context, s Secret) (string, error) {
+	id := make([]byte, 20)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	handle := HandlePrefix + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(id))
+	s.Owner = owner(ctx)
+	s.Created = v.now().UTC()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.secrets[handle] = s
+	dropped := v.evict()
+	if err := v.save(); err != nil {
+		delete(v.secrets, handle)
+		for h, d := range dropped {
+			v.secrets[h] = d
+		}
+		return "", err
+	}
+	return handle, nil
+}
+
+// expired reports whether s has outlived the vault's TTL.
+func (v *Vault) expired(s Secret) bool {
+	return v.TTL > 0 && v.now().After(s.Created.Add(v.TTL))
+}
+
+// evict drops expired secrets and then the oldest while there are more than
+// MaxSecrets, and returns what it dropped; the caller holds v.mu for writing.
+func (v *Vault) evict() map[string]Secret {
+	dropped := make(map[string]Secret)
+	for handle, s := range v.secrets {
+		if v.expired(s) {
+			dropped[handle] = s
+			delete(v.secrets, handle)
+		}
+	}
+	if v.MaxSecrets <= 0 || len(v.secrets) <= v.MaxSecrets {
+		return dropped
+	}
+	handles := make([]string, 0, len(v.secrets))
+	for handle := range v.secrets {
+		handles = append(handles, handle)
+	}
+	sort.Slice(handles, func(i, j int) bool {
+		return v.secrets[handles[i]].Created.Before(v.secrets[handles[j]].Created)
+	})
+	for _, handle := range handles[:len(handles)-v.MaxSecrets] {
+		dropped[handle] = v.secrets[handle]
+		delete(v.secrets, handle)
+	}
+	return dropped
+}
+
+// Get returns the secret behind handle if it belongs to the client in ctx.
+func (v *Vault) Get(ctx context.Context, handle string) (Secret, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	s, ok := v.secrets[handle]
+	if !ok || s.Owner != owner(ctx) || v.expired(s) {
+		return Secret{}, fmt.Errorf("%w: %s", ErrNotFound, handle)
+	}
+	return s, nil
+}
+
+// Delete removes the secret behind handle if it belongs to the client in ctx.
+func (v *Vault) Delete(ctx context.Context, handle string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.secrets[handle]
+	if !ok || s.Owner != owner(ctx) {
+		return fmt.Errorf("%w: %s", ErrNotFound, handle)
+	}
+	delete(v.secrets, handle)
+	if err := v.save(); err != nil {
+		v.secrets[handle] = s
+		return err
+	}
+	return nil
+}
+
+// save persists the secrets; the caller holds v.mu.
+func (v *Vault) save() error {
+	if v.store == nil {
+		return nil
+	}
+	return v.store.save(v.secrets)
+}
+
+// owner identifies the client a secret belongs to. Secrets created without
+// inbound authentication (STDIO or an open HTTP port) share the empty owner.
+func owner(ctx context.Context) string {
+	if p, ok := auth.PrincipalFromContext(ctx); ok {
+		return p.ID()
+	}
+	return ""
+}
+
+// Load builds the vault described by cfg.
+func Load(cfg *config.VaultConfig) (*Vault, error) {
+	v := New()
+	if cfg.File != "" {
+		var err error
+		if v, err = Open(cfg.File, cfg.Key); err != nil {
+			return nil, err
+		}
+	}
+	v.AllowReveal = cfg.AllowReveal
+	v.ExportDir = cfg.ExportDir
+	v.TTL = cfg.TTL
+	v.MaxSecrets = cfg.MaxSecrets
+	// Secrets that expired while the server was down are not kept on disk
+	if dropped := v.evict(); len(dropped) > 0 {
+		if err := v.save(); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
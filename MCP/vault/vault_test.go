@@ -0,0 +1,166 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker-hub-api/mcp-server/auth"
+	"github.com/docker-hub-api/mcp-server/config"
+)
+
+func TestVaultOwners(t *testing.T) {
+	v := New()
+	alice := auth.WithPrincipal(context.Background(), &auth.Principal{Method: "bearer", Subject: "alice"})
+	handle, err := v.Put(alice, Secret{Value: "dckr_pat_1", Kind: "access_token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(handle, HandlePrefix) {
+		t.Errorf("handle %q lacks %q", handle, HandlePrefix)
+	}
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		found bool
+	}{
+		{"owner", alice, true},
+		{"same subject by another method", auth.WithPrincipal(context.Background(), &auth.Principal{Method: "api_key", Subject: "alice"}), false},
+		{"another client", auth.WithPrincipal(context.Background(), &auth.Principal{Method: "bearer", Subject: "bob"}), false},
+		{"unauthenticated", context.Background(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := v.Get(tt.ctx, handle)
+			if tt.found && (err != nil || s.Value != "dckr_pat_1" || s.Owner != "bearer:alice") {
+				t.Errorf("got %+v, %v", s, err)
+			}
+			if !tt.found && !errors.Is(err, ErrNotFound) {
+				t.Errorf("err = %v, want %v", err, ErrNotFound)
+			}
+			if !tt.found && !errors.Is(v.Delete(tt.ctx, handle), ErrNotFound) {
+				t.Errorf("another client deleted the secret")
+			}
+		})
+	}
+	if err := v.Delete(alice, handle); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Get(alice, handle); !errors.Is(err, ErrNotFound) {
+		t.Errorf("deleted secret: err = %v", err)
+	}
+}
+
+func TestVaultLimits(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_800_000_000, 0)
+	v := New()
+	v.now = func() time.Time { return now }
+	v.TTL = time.Hour
+	v.MaxSecrets = 2
+
+	put := func(value string) string {
+		t.Helper()
+		handle, err := v.Put(ctx, Secret{Value: value})
+		if err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Minute)
+		return handle
+	}
+	first, second, third := put("1"), put("2"), put("3")
+	if _, err := v.Get(ctx, first); !errors.Is(err, ErrNotFound) {
+		t.Errorf("oldest secret beyond MaxSecrets: err = %v", err)
+	}
+	for _, h := range []string{second, third} {
+		if _, err := v.Get(ctx, h); err != nil {
+			t.Errorf("newer secret: %v", err)
+		}
+	}
+
+	now = now.Add(time.Hour - time.Minute)
+	if _, err := v.Get(ctx, second); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired secret: err = %v", err)
+	}
+	if _, err := v.Get(ctx, third); err != nil {
+		t.Errorf("unexpired secret: %v", err)
+	}
+	put("4")
+	if len(v.secrets) != 2 {
+		t.Errorf("kept %d secrets, want the expired one dropped", len(v.secrets))
+	}
+
+	v.TTL = 0
+	now = now.Add(1000 * time.Hour)
+	if _, err := v.Get(ctx, third); err != nil {
+		t.Errorf("TTL 0 keeps secrets: %v", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	key := bytes.Repeat([]byte{7}, KeySize)
+	path := filepath.Join(t.TempDir(), "vault")
+
+	v, err := Load(&config.VaultConfig{File: path, Key: key, TTL: time.Hour, MaxSecrets: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle, err := v.Put(ctx, Secret{Value: "dckr_pat_secret", Kind: "access_token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("dckr_pat_secret")) || bytes.Contains(data, []byte(handle)) {
+		t.Error("vault file holds plaintext")
+	}
+
+	reopened, err := Open(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := reopened.Get(ctx, handle); err != nil || s.Value != "dckr_pat_secret" {
+		t.Errorf("reopened vault: %+v, %v", s, err)
+	}
+
+	tests := []struct {
+		name string
+		key  []byte
+		data []byte
+		want string
+	}{
+		{"wrong key", bytes.Repeat([]byte{8}, KeySize), data, "wrong key or corrupted file"},
+		{"short key", key[:16], data, "must be 32 bytes"},
+		{"altered", key, append(append([]byte{}, data[:len(data)-1]...), data[len(data)-1]^1), "wrong key or corrupted file"},
+		{"truncated", key, data[:4], "truncated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), "vault")
+			os.WriteFile(p, tt.data, 0o600)
+			if _, err := Open(p, tt.key); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+
+	// Secrets that expired while the server was down are dropped on load
+	expired, err := Load(&config.VaultConfig{File: path, Key: key, TTL: time.Nanosecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired.secrets) != 0 {
+		t.Errorf("kept %d expired secrets", len(expired.secrets))
+	}
+	if reloaded, _ := Open(path, key); len(reloaded.secrets) != 0 {
+		t.Errorf("expired secrets are still in the file")
+	}
+}